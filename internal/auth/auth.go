@@ -1,19 +1,95 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
 	"time"
+
 	"github.com/golang-jwt/jwt/v4"
 )
 
 var SecretKey = "secret-yy-xz"
 
-func GenerateJWT(userID uint) (string, error) {
-	claims:= jwt.MapClaims{
-		"user_id": userID,
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
+const (
+	Issuer   = "food-delivery-workshop"
+	Audience = "food-delivery-api"
+	tokenTTL = 24 * time.Hour
+)
+
+// Claims are the claims of the access tokens issued by the API.
+type Claims struct {
+	UserID uint     `json:"user_id"`
+	Roles  []string `json:"roles,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Valid also checks the issuer and audience, which jwt only checks on request.
+func (c *Claims) Valid() error {
+	if err := c.RegisteredClaims.Valid(); err != nil {
+		return err
+	}
+	if !c.VerifyIssuer(Issuer, true) {
+		return errors.New("token has an invalid issuer")
+	}
+	if !c.VerifyAudience(Audience, true) {
+		return errors.New("token has an invalid audience")
+	}
+	if c.UserID == 0 {
+		return errors.New("token has no user")
+	}
+	return nil
+}
+
+// Principal is the user the token was issued to.
+func (c *Claims) Principal() *Principal {
+	return &Principal{UserID: c.UserID, Roles: c.Roles, TokenID: c.ID}
+}
+
+// ParseJWT verifies an access token issued by GenerateJWT. The HTTP routes use
+// the Fiber JWT middleware with the same claims; this is for other transports.
+func ParseJWT(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return []byte(SecretKey), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func GenerateJWT(userID uint, roles []string) (string, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims := &Claims{
+		UserID: userID,
+		Roles:  roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    Issuer,
+			Audience:  jwt.ClaimStrings{Audience},
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenTTL)),
+		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(SecretKey))
 }
 
-
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
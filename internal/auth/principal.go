@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/models"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+// Scopes that can be granted to API keys. Signed in users get the scopes of
+// their roles, see roleScopes.
+const (
+	ScopeAdmin            = "admin"
+	ScopeProductsRead     = "products:read"
+	ScopeProductsWrite    = "products:write"
+	ScopePromotionsRead   = "promotions:read"
+	ScopePromotionsWrite  = "promotions:write"
+	ScopeRestaurantsRead  = "restaurants:read"
+	ScopeRestaurantsWrite = "restaurants:write"
+	ScopeOrdersWrite      = "orders:write"
+)
+
+var Scopes = []string{
+	ScopeAdmin,
+	ScopeProductsRead, ScopeProductsWrite,
+	ScopePromotionsRead, ScopePromotionsWrite,
+	ScopeRestaurantsRead, ScopeRestaurantsWrite,
+	ScopeOrdersWrite,
+}
+
+// roleScopes are the scopes each user role grants. Customers can only read;
+// managing restaurants, the catalogue and orders needs staff or admin.
+var roleScopes = map[string][]string{
+	models.RoleCustomer: {ScopeProductsRead, ScopePromotionsRead, ScopeRestaurantsRead},
+	models.RoleStaff: {
+		ScopeProductsRead, ScopeProductsWrite,
+		ScopePromotionsRead, ScopePromotionsWrite,
+		ScopeRestaurantsRead, ScopeRestaurantsWrite,
+		ScopeOrdersWrite,
+	},
+	models.RoleAdmin: {ScopeAdmin},
+}
+
+type principalKey struct{}
+
+// Principal is who made the request: a signed in user or a service client
+// holding an API key.
+type Principal struct {
+	UserID   uint
+	Roles    []string
+	TokenID  string
+	APIKeyID uint
+	Scopes   []string
+}
+
+func (p *Principal) IsUser() bool {
+	return p.UserID != 0
+}
+
+// Key identifies the caller for per-caller state such as rate limits, e.g.
+// "user:42" or "apikey:7".
+func (p *Principal) Key() string {
+	if p.IsUser() {
+		return "user:" + strconv.FormatUint(uint64(p.UserID), 10)
+	}
+	return "apikey:" + strconv.FormatUint(uint64(p.APIKeyID), 10)
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, granted := range p.Roles {
+		if granted == role {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantedScopes returns the scopes of an API key, or those of a user's roles.
+// Users without roles are treated as customers.
+func (p *Principal) GrantedScopes() []string {
+	if !p.IsUser() {
+		return p.Scopes
+	}
+	if len(p.Roles) == 0 {
+		return roleScopes[models.RoleCustomer]
+	}
+	var scopes []string
+	for _, role := range p.Roles {
+		scopes = append(scopes, roleScopes[role]...)
+	}
+	return scopes
+}
+
+// Allows reports whether the principal may use an endpoint guarded by scope.
+// The admin scope allows everything.
+func (p *Principal) Allows(scope string) bool {
+	for _, granted := range p.GrantedScopes() {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// SetPrincipal stores the principal in the request's user context, which is
+// what endpoints pass on to services.
+func SetPrincipal(c *fiber.Ctx, principal *Principal) {
+	c.SetUserContext(NewContext(c.UserContext(), principal))
+}
+
+// CurrentUser returns the signed in user. Requests made with an API key are
+// rejected because they do not act on behalf of a user.
+func CurrentUser(c *fiber.Ctx) (*Principal, error) {
+	return UserFromContext(c.UserContext())
+}
+
+// UserFromContext is CurrentUser for callers that only have the context.
+func UserFromContext(ctx context.Context) (*Principal, error) {
+	principal, ok := FromContext(ctx)
+	if !ok {
+		return nil, apperror.Unauthorized("UNAUTHORIZED", "Unauthorized")
+	}
+	if !principal.IsUser() {
+		return nil, apperror.Forbidden("USER_REQUIRED", "this endpoint requires a signed in user")
+	}
+	return principal, nil
+}
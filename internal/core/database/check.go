@@ -0,0 +1,31 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Check reports whether db answers and every table in Models exists, i.e. the
+// migrations have been applied.
+func Check(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping database: %w", err)
+	}
+
+	migrator := db.WithContext(ctx).Migrator()
+	for _, model := range Models {
+		if !migrator.HasTable(model) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("table for %T is missing", model)
+		}
+	}
+	return nil
+}
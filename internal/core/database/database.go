@@ -5,6 +5,7 @@ import (
 	 "food-delivery-workshop/internal/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 	"log"
 )
 
@@ -13,7 +14,15 @@ var (
 	err error
 )
 
-func ConnectDB() {
+// Models are migrated on start up, and Check expects all of their tables.
+var Models = []interface{}{&models.User{}, &models.Cart{}, &models.CartItem{}, &models.Promotion{}, &models.Product{},
+	&models.Restaurant{}, &models.Order{}, &models.OrderItem{}, &models.Rider{}, &models.RiderAssignment{},
+	&models.OpeningHour{}, &models.Holiday{}, &models.UserToken{}, &models.LoginAttempt{}, &models.LoginOTP{}, &models.UserIdentity{}, &models.APIKey{},
+	&models.WebhookSubscription{}, &models.WebhookDelivery{}, &models.OutboxEvent{}}
+
+// ConnectDB connects and migrates. Queries are logged through gormLogger
+// instead of printing every statement.
+func ConnectDB(gormLogger logger.Interface) {
 
 	host := "localhost"
 	port := "5432"
@@ -23,14 +32,13 @@ func ConnectDB() {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		host, port, user, password, dbname)
 
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormLogger})
 
 	if err != nil {
-		log.Fatal("Error connecting to the database %v", err)
+		log.Fatalf("Error connecting to the database %v", err)
 	}
 
-	DB = DB.Debug()
-	err = DB.AutoMigrate(&models.User{}, &models.Cart{}, &models.CartItem{}, &models.Promotion{}, &models.Product{})
+	err = DB.AutoMigrate(Models...)
 	if err != nil {
 		log.Fatalf("failed to migrate: %v", err)
 	}
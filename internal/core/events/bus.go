@@ -0,0 +1,66 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Message is an event as handlers receive it from the outbox.
+type Message struct {
+	ID         string // เหมือนเดิมทุกครั้งที่ส่งซ้ำ ใช้กันประมวลผลซ้ำได้
+	Name       string
+	Payload    json.RawMessage
+	OccurredAt time.Time
+}
+
+type Handler func(ctx context.Context, message Message) error
+
+// Bus dispatches outbox messages to the handlers subscribed to their name.
+// Delivery is at least once: when one handler fails the whole message is
+// retried, so every handler must cope with seeing a message twice.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: map[string][]Handler{}}
+}
+
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// On subscribes a handler that receives the decoded event.
+func On[E Event](b *Bus, handler func(ctx context.Context, message Message, event E) error) {
+	var zero E
+	b.Subscribe(zero.EventName(), func(ctx context.Context, message Message) error {
+		var event E
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return fmt.Errorf("decode %s: %w", message.Name, err)
+		}
+		return handler(ctx, message, event)
+	})
+}
+
+// Dispatch runs every handler of the message, even after one fails, and
+// returns their errors joined.
+func (b *Bus) Dispatch(ctx context.Context, message Message) error {
+	b.mu.RLock()
+	handlers := b.handlers[message.Name]
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
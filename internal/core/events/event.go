@@ -0,0 +1,84 @@
+package events
+
+import (
+	"food-delivery-workshop/internal/models"
+	"time"
+)
+
+// Event is a domain event. Name identifies it on the bus and in the outbox.
+type Event interface {
+	EventName() string
+}
+
+const (
+	NameProductPriceChanged = "ProductPriceChanged"
+	NameProductUpdated      = "ProductUpdated"
+	NameCartUpdated         = "CartUpdated"
+	NamePromotionApplied    = "PromotionApplied"
+	NamePromotionRedeemed   = "PromotionRedeemed"
+	NameOrderPlaced         = "OrderPlaced"
+	NameOrderStatusChanged  = "OrderStatusChanged"
+)
+
+type ProductPriceChanged struct {
+	ProductID uint    `json:"product_id"`
+	OldPrice  float64 `json:"old_price"`
+	NewPrice  float64 `json:"new_price"`
+}
+
+func (ProductPriceChanged) EventName() string { return NameProductPriceChanged }
+
+// ProductUpdated is raised on every product update, with the product as saved.
+type ProductUpdated struct {
+	Product *models.Product `json:"product"`
+}
+
+func (ProductUpdated) EventName() string { return NameProductUpdated }
+
+type CartUpdated struct {
+	CartID    uint `json:"cart_id"`
+	UserID    uint `json:"user_id"`
+	ItemCount int  `json:"item_count"` // 0 เมื่อตะกร้าถูกลบ
+}
+
+func (CartUpdated) EventName() string { return NameCartUpdated }
+
+type PromotionApplied struct {
+	CartID      uint    `json:"cart_id"`
+	UserID      uint    `json:"user_id"`
+	PromotionID uint    `json:"promotion_id"`
+	Discount    float64 `json:"discount"`
+}
+
+func (PromotionApplied) EventName() string { return NamePromotionApplied }
+
+type OrderPlaced struct {
+	OrderID      uint          `json:"order_id"`
+	UserID       uint          `json:"user_id"`
+	RestaurantID uint          `json:"restaurant_id"`
+	PromotionID  *uint         `json:"promotion_id"`
+	Total        float64       `json:"total"`
+	ScheduledFor *time.Time    `json:"scheduled_for"`
+	Order        *models.Order `json:"order"` // ออเดอร์พร้อม item และ ETA ตามที่ส่งให้ webhook
+}
+
+func (OrderPlaced) EventName() string { return NameOrderPlaced }
+
+// PromotionRedeemed is raised when an order is placed with a promotion.
+type PromotionRedeemed struct {
+	OrderID     uint    `json:"order_id"`
+	UserID      uint    `json:"user_id"`
+	PromotionID uint    `json:"promotion_id"`
+	Discount    float64 `json:"discount"`
+}
+
+func (PromotionRedeemed) EventName() string { return NamePromotionRedeemed }
+
+type OrderStatusChanged struct {
+	OrderID   uint        `json:"order_id"`
+	Status    string      `json:"status"`
+	ETA       *models.ETA `json:"eta"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+func (OrderStatusChanged) EventName() string { return NameOrderStatusChanged }
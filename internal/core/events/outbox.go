@@ -0,0 +1,206 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Recorder is what services use to raise events: Record inside Transaction
+// stores the events in the outbox together with the business change, so
+// either both are saved or neither is.
+type Recorder interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+	Record(ctx context.Context, events ...Event) error
+}
+
+type Config struct {
+	BatchSize int
+	Lease     time.Duration // เวลาที่ batch ถูกจองไว้ ถ้า process ตายระหว่างส่ง event จะถูกส่งใหม่หลังจากนี้
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Retention time.Duration // ลบ event ที่ส่งแล้วเมื่อเก่ากว่านี้
+}
+
+func DefaultConfig() Config {
+	return Config{
+		BatchSize: 100,
+		Lease:     time.Minute,
+		BaseDelay: time.Second,
+		MaxDelay:  10 * time.Minute,
+		Retention: 7 * 24 * time.Hour,
+	}
+}
+
+// Outbox stores events in the outbox table and relays them to the bus.
+type Outbox struct {
+	db     *gorm.DB
+	bus    *Bus
+	config Config
+	wake   chan struct{}
+}
+
+func NewOutbox(db *gorm.DB, bus *Bus, config Config) *Outbox {
+	return &Outbox{db: db, bus: bus, config: config, wake: make(chan struct{}, 1)}
+}
+
+// Transaction commits fn and then wakes the relay, so events go out without
+// waiting for the next poll.
+func (o *Outbox) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := database.Transaction(ctx, o.db, fn); err != nil {
+		return err
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (o *Outbox) Record(ctx context.Context, events ...Event) error {
+	now := time.Now()
+	rows := make([]*models.OutboxEvent, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		eventID, err := newEventID()
+		if err != nil {
+			return err
+		}
+		rows = append(rows, &models.OutboxEvent{
+			EventID:       eventID,
+			Name:          event.EventName(),
+			Payload:       payload,
+			NextAttemptAt: now,
+		})
+	}
+	return database.Conn(ctx, o.db).Create(rows).Error
+}
+
+// Start relays events every interval, or as soon as a transaction with events
+// commits, until ctx is cancelled.
+func (o *Outbox) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		lastPurge := time.Time{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-o.wake:
+			}
+
+			// ส่งต่อจนหมด batch ที่ค้างอยู่ก่อนกลับไปรอ
+			for {
+				relayed, err := o.RelayDue(ctx)
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("relay outbox error: %v", err)
+					break
+				}
+				if relayed < o.config.BatchSize {
+					break
+				}
+			}
+
+			if time.Since(lastPurge) > time.Hour {
+				if err := o.purge(ctx, time.Now().Add(-o.config.Retention)); err != nil {
+					logrus.WithContext(ctx).Errorf("purge outbox error: %v", err)
+				}
+				lastPurge = time.Now()
+			}
+		}
+	}()
+}
+
+// RelayDue dispatches a batch of due events in the order they were recorded
+// and returns how many it claimed. Failed events are retried with backoff.
+func (o *Outbox) RelayDue(ctx context.Context) (int, error) {
+	events, err := o.claimDue(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		message := Message{ID: event.EventID, Name: event.Name, Payload: event.Payload, OccurredAt: event.CreatedAt}
+		now := time.Now()
+		event.Attempts++
+		if err := o.bus.Dispatch(ctx, message); err != nil {
+			event.LastError = err.Error()
+			event.NextAttemptAt = now.Add(o.backoff(event.Attempts))
+			logrus.WithContext(ctx).WithFields(logrus.Fields{
+				"event_id": event.EventID,
+				"event":    event.Name,
+				"attempts": event.Attempts,
+			}).Errorf("outbox event handler error: %v", err)
+		} else {
+			event.PublishedAt = &now
+			event.LastError = ""
+		}
+		if err := database.Conn(ctx, o.db).Save(event).Error; err != nil {
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+// claimDue locks due events and pushes their next attempt back by the lease
+// so another instance does not relay them at the same time.
+func (o *Outbox) claimDue(ctx context.Context, now time.Time) ([]*models.OutboxEvent, error) {
+	var events []*models.OutboxEvent
+	err := database.Transaction(ctx, o.db, func(ctx context.Context) error {
+		err := database.Conn(ctx, o.db).Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", now).
+			Order("id").Limit(o.config.BatchSize).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]uint, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		return database.Conn(ctx, o.db).Model(&models.OutboxEvent{}).Where("id IN ?", ids).
+			UpdateColumn("next_attempt_at", now.Add(o.config.Lease)).Error
+	})
+	return events, err
+}
+
+func (o *Outbox) purge(ctx context.Context, before time.Time) error {
+	return database.Conn(ctx, o.db).Unscoped().
+		Where("published_at < ?", before).
+		Delete(&models.OutboxEvent{}).Error
+}
+
+// backoff never gives up: an event stays in the outbox until every handler
+// has accepted it.
+func (o *Outbox) backoff(attempts int) time.Duration {
+	if attempts > 30 {
+		return o.config.MaxDelay
+	}
+	delay := o.config.BaseDelay << (attempts - 1)
+	if delay > o.config.MaxDelay {
+		delay = o.config.MaxDelay
+	}
+	return delay
+}
+
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,89 @@
+package idempotency
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry is what is kept for one key: the fingerprint of the first request and,
+// once it has finished, the response to replay.
+type Entry struct {
+	Fingerprint string
+	Done        bool
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Store keeps idempotency entries. MemoryStore is enough for a single
+// instance; deployments with several instances need a shared implementation
+// (e.g. Redis) so a retry that lands on another instance is still replayed.
+type Store interface {
+	// Reserve atomically stores a pending entry for key when there is none and
+	// returns nil. Otherwise it returns the existing entry untouched. The
+	// pending entry is forgotten after lease, so a crashed request does not
+	// hold the key forever.
+	Reserve(key, fingerprint string, lease time.Duration) (*Entry, error)
+	// Complete stores the finished entry for ttl.
+	Complete(key string, entry Entry, ttl time.Duration) error
+	// Release forgets key so the request can be retried.
+	Release(key string) error
+}
+
+type memoryEntry struct {
+	entry     Entry
+	expiresAt time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*memoryEntry{}}
+}
+
+func (s *MemoryStore) Reserve(key, fingerprint string, lease time.Duration) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if existing := s.entries[key]; existing != nil && now.Before(existing.expiresAt) {
+		entry := existing.entry
+		return &entry, nil
+	}
+	s.entries[key] = &memoryEntry{entry: Entry{Fingerprint: fingerprint}, expiresAt: now.Add(lease)}
+
+	s.sweep(now)
+	return nil, nil
+}
+
+func (s *MemoryStore) Complete(key string, entry Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.Done = true
+	s.entries[key] = &memoryEntry{entry: entry, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep drops expired entries so the map does not grow with every key seen.
+func (s *MemoryStore) sweep(now time.Time) {
+	if len(s.entries) < 1024 {
+		return
+	}
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package lockout
+
+import (
+	"time"
+)
+
+// Policy controls how failures turn into delays. The first FreeAttempts
+// failures cost nothing; after that each failure doubles the wait, starting at
+// BaseDelay and capped at MaxDelay, which acts as the temporary lockout.
+type Policy struct {
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	// Window is how long counters are kept after the last failure.
+	Window time.Duration
+}
+
+var (
+	DefaultAccountPolicy = Policy{FreeAttempts: 5, BaseDelay: 30 * time.Second, MaxDelay: 30 * time.Minute, Window: 24 * time.Hour}
+	DefaultIPPolicy      = Policy{FreeAttempts: 20, BaseDelay: 10 * time.Second, MaxDelay: 15 * time.Minute, Window: time.Hour}
+)
+
+type Limiter struct {
+	store  Store
+	prefix string
+	policy Policy
+}
+
+// NewLimiter creates a limiter whose keys are namespaced with prefix, so
+// several limiters can share one store.
+func NewLimiter(store Store, prefix string, policy Policy) *Limiter {
+	return &Limiter{store: store, prefix: prefix, policy: policy}
+}
+
+// Check returns how long the caller must wait before the next attempt, or 0.
+func (l *Limiter) Check(key string) (time.Duration, error) {
+	attempts, err := l.store.Get(l.prefix + key)
+	if err != nil {
+		return 0, err
+	}
+	return l.wait(attempts, time.Now()), nil
+}
+
+// Attempt counts an attempt before the caller checks the credentials, so
+// concurrent requests cannot all pass before the first failure is recorded.
+// It returns the wait without counting anything if the key is locked, or 0.
+// Callers Release or Reset the attempt when it succeeds.
+func (l *Limiter) Attempt(key string) (time.Duration, error) {
+	now := time.Now()
+	attempts, counted, err := l.store.Take(l.prefix+key, now, l.policy.Window, func(attempts Attempts) bool {
+		return l.wait(attempts, now) == 0
+	})
+	if err != nil {
+		return 0, err
+	}
+	if counted {
+		return 0, nil
+	}
+	return l.wait(attempts, now), nil
+}
+
+// Release gives back an attempt that turned out not to be a failure.
+func (l *Limiter) Release(key string) error {
+	return l.store.Release(l.prefix + key)
+}
+
+func (l *Limiter) Reset(key string) error {
+	return l.store.Reset(l.prefix + key)
+}
+
+func (l *Limiter) wait(attempts Attempts, now time.Time) time.Duration {
+	over := attempts.Failures - l.policy.FreeAttempts
+	if over <= 0 {
+		return 0
+	}
+
+	delay := l.policy.MaxDelay
+	if over < 32 {
+		delay = l.policy.BaseDelay << (over - 1)
+	}
+	if delay > l.policy.MaxDelay || delay <= 0 {
+		delay = l.policy.MaxDelay
+	}
+
+	if wait := attempts.LastFailedAt.Add(delay).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
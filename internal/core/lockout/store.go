@@ -0,0 +1,103 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+// Attempts is the failure history kept for one key.
+type Attempts struct {
+	Failures     int
+	LastFailedAt time.Time
+}
+
+// Store keeps failure counters. MemoryStore is enough for a single instance;
+// deployments with several instances need a shared implementation (e.g. Redis)
+// so an attacker cannot spread attempts across instances.
+type Store interface {
+	// Take increments the counter for key if allowed accepts the current
+	// attempts, checking and counting in one step. It returns the attempts and
+	// whether one was counted. The counter is forgotten after ttl without further attempts.
+	Take(key string, at time.Time, ttl time.Duration, allowed func(Attempts) bool) (Attempts, bool, error)
+	// Release gives back one attempt counted by Take.
+	Release(key string) error
+	Get(key string) (Attempts, error)
+	Reset(key string) error
+}
+
+type memoryEntry struct {
+	attempts  Attempts
+	expiresAt time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*memoryEntry{}}
+}
+
+func (s *MemoryStore) Take(key string, at time.Time, ttl time.Duration, allowed func(Attempts) bool) (Attempts, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entries[key]
+	if entry == nil || at.After(entry.expiresAt) {
+		entry = &memoryEntry{}
+	}
+	if !allowed(entry.attempts) {
+		return entry.attempts, false, nil
+	}
+	entry.attempts.Failures++
+	entry.attempts.LastFailedAt = at
+	entry.expiresAt = at.Add(ttl)
+	s.entries[key] = entry
+
+	s.sweep(at)
+	return entry.attempts, true, nil
+}
+
+func (s *MemoryStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entries[key]
+	if entry == nil || time.Now().After(entry.expiresAt) || entry.attempts.Failures == 0 {
+		return nil
+	}
+	entry.attempts.Failures--
+	return nil
+}
+
+func (s *MemoryStore) Get(key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entries[key]
+	if entry == nil || time.Now().After(entry.expiresAt) {
+		return Attempts{}, nil
+	}
+	return entry.attempts, nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep drops expired entries so the map does not grow with every IP seen.
+func (s *MemoryStore) sweep(now time.Time) {
+	if len(s.entries) < 1024 {
+		return
+	}
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// GormLogger writes GORM logs through logrus with the request ID of the query's
+// context. Failed queries are logged as errors and queries slower than
+// SlowThreshold as warnings; every statement is only logged at debug level.
+type GormLogger struct {
+	SlowThreshold time.Duration
+	level         logger.LogLevel
+}
+
+func NewGormLogger(slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{SlowThreshold: slowThreshold, level: logger.Warn}
+}
+
+func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *GormLogger) Info(ctx context.Context, message string, args ...interface{}) {
+	if l.level >= logger.Info {
+		logrus.WithContext(ctx).Infof(message, args...)
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, message string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		logrus.WithContext(ctx).Warnf(message, args...)
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, message string, args ...interface{}) {
+	if l.level >= logger.Error {
+		logrus.WithContext(ctx).Errorf(message, args...)
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	slow := l.SlowThreshold > 0 && elapsed > l.SlowThreshold
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	if !failed && !slow && !logrus.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
+
+	sql, rows := fc()
+	entry := logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"sql":         sql,
+		"rows":        rows,
+		"duration_ms": float64(elapsed.Microseconds()) / 1000,
+	})
+	switch {
+	case failed && l.level >= logger.Error:
+		entry.WithError(err).Error("query failed")
+	case slow && l.level >= logger.Warn:
+		entry.Warn(fmt.Sprintf("slow query over %s", l.SlowThreshold))
+	default:
+		entry.Debug("query")
+	}
+}
@@ -0,0 +1,126 @@
+// Package logging configures logrus for structured JSON logs and carries the
+// request ID through context.Context, so every line written with
+// logrus.WithContext(ctx) can be traced back to its request.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Config struct {
+	Level              logrus.Level
+	Format             string // json หรือ text สำหรับอ่านบนเครื่อง dev
+	SlowQueryThreshold time.Duration
+}
+
+// ConfigFromEnv reads LOG_LEVEL (default info), LOG_FORMAT (json or text,
+// default json) and DB_SLOW_QUERY_THRESHOLD (default 200ms, 0 turns it off).
+func ConfigFromEnv() (Config, error) {
+	config := Config{Level: logrus.InfoLevel, Format: "json", SlowQueryThreshold: 200 * time.Millisecond}
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		level, err := logrus.ParseLevel(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+		}
+		config.Level = level
+	}
+	if value := os.Getenv("LOG_FORMAT"); value != "" {
+		if value != "json" && value != "text" {
+			return config, fmt.Errorf("invalid LOG_FORMAT %q, want json or text", value)
+		}
+		config.Format = value
+	}
+	if value := os.Getenv("DB_SLOW_QUERY_THRESHOLD"); value != "" {
+		threshold, err := time.ParseDuration(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid DB_SLOW_QUERY_THRESHOLD: %w", err)
+		}
+		config.SlowQueryThreshold = threshold
+	}
+	return config, nil
+}
+
+// Setup configures the standard logrus logger and sends the standard library
+// log package through it too.
+func Setup(config Config) {
+	logger := logrus.StandardLogger()
+	logger.SetLevel(config.Level)
+	if config.Format == "text" {
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	} else {
+		logger.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	}
+	logger.AddHook(&contextHook{})
+	logger.AddHook(&redactHook{})
+
+	log.SetFlags(0)
+	log.SetOutput(stdlogWriter{})
+}
+
+// stdlogWriter logs synchronously, unlike logrus' Writer, so the message of
+// log.Fatal is written before the process exits.
+type stdlogWriter struct{}
+
+func (stdlogWriter) Write(p []byte) (int, error) {
+	logrus.Info(strings.TrimSpace(string(p)))
+	return len(p), nil
+}
+
+type requestIDKey struct{}
+
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID keeps an ID sent by the client or a proxy when it looks sane, so
+// logs can be followed across services, and otherwise generates one.
+func RequestID(incoming string) string {
+	if validRequestID.MatchString(incoming) {
+		return strings.Clone(incoming) // header จาก fiber อ้าง buffer ที่ถูกใช้ซ้ำ
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// contextHook adds the request ID, and the trace and span IDs when the request
+// is traced, to entries logged with WithContext.
+type contextHook struct{}
+
+func (h *contextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *contextHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	if requestID := RequestIDFromContext(entry.Context); requestID != "" {
+		entry.Data["request_id"] = requestID
+	}
+	if spanContext := trace.SpanContextFromContext(entry.Context); spanContext.IsValid() {
+		entry.Data["trace_id"] = spanContext.TraceID().String()
+		entry.Data["span_id"] = spanContext.SpanID().String()
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are field names whose values are never logged.
+var sensitiveKeys = []string{"password", "token", "secret", "api_key", "apikey", "authorization", "id_card", "idcard", "otp"}
+
+var (
+	// password=..., "token":"...", api_key: ... และ "password" = '...' ใน SQL
+	keyValuePattern = regexp.MustCompile(`(?i)("?(?:` + strings.Join(sensitiveKeys, "|") + `)[a-z_]*"?\s*[:=]\s*)("[^"]*"|'[^']*'|[^\s,;&)]+)`)
+	bearerPattern   = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`)
+	jwtPattern      = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	bcryptPattern   = regexp.MustCompile(`\$2[aby]\$\d{2}\$[./A-Za-z0-9]{53}`)
+	// เลขบัตรประชาชน 13 หลัก ทั้งแบบติดกันและแบบมีขีด 1-2345-67890-12-3
+	idCardPattern = regexp.MustCompile(`\b\d{13}\b|\b\d-\d{4}-\d{5}-\d{2}-\d\b`)
+)
+
+// Redact masks passwords, tokens, password hashes and ID card numbers in s.
+func Redact(s string) string {
+	s = keyValuePattern.ReplaceAllString(s, "${1}"+redacted)
+	s = bearerPattern.ReplaceAllString(s, "${1}"+redacted)
+	s = jwtPattern.ReplaceAllString(s, redacted)
+	s = bcryptPattern.ReplaceAllString(s, redacted)
+	return idCardPattern.ReplaceAllString(s, redacted)
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHook applies Redact to the message and the string fields of every
+// entry, and drops the values of fields with sensitive names.
+type redactHook struct{}
+
+func (h *redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = Redact(entry.Message)
+	for key, value := range entry.Data {
+		if isSensitiveKey(key) {
+			entry.Data[key] = redacted
+			continue
+		}
+		switch value := value.(type) {
+		case string:
+			entry.Data[key] = Redact(value)
+		case error:
+			entry.Data[key] = Redact(value.Error())
+		}
+	}
+	return nil
+}
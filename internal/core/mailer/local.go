@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type consoleMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+// NewConsoleMailer writes every email to w instead of sending it.
+func NewConsoleMailer(w io.Writer, from string) Mailer {
+	return &consoleMailer{w: w, from: from}
+}
+
+func (m *consoleMailer) Send(message *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := fmt.Fprintf(m.w, "----- email -----\n%s-----------------\n", format(m.from, message))
+	return err
+}
+
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer saves every email as an .eml file in dir.
+func NewFileMailer(dir string, from string) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileMailer{dir: dir, from: from}, nil
+}
+
+func (m *fileMailer) Send(message *Message) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), filepath.Base(message.To))
+	return os.WriteFile(filepath.Join(m.dir, name), format(m.from, message), 0o644)
+}
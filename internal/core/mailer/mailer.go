@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers plain text email.
+type Mailer interface {
+	Send(message *Message) error
+}
+
+// FromEnv picks the mailer from MAIL_DRIVER (smtp, file or console, default
+// console) so local development needs no mail server.
+func FromEnv() (Mailer, error) {
+	from := getenv("MAIL_FROM", "no-reply@food-delivery.local")
+	switch driver := getenv("MAIL_DRIVER", "console"); driver {
+	case "smtp":
+		port, err := strconv.Atoi(getenv("SMTP_PORT", "587"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP_PORT: %w", err)
+		}
+		return NewSMTPMailer(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}), nil
+	case "file":
+		return NewFileMailer(getenv("MAIL_DIR", "tmp/mail"), from)
+	case "console":
+		return NewConsoleMailer(os.Stdout, from), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+}
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// format renders the message as a minimal RFC 5322 email.
+func format(from string, message *Message) []byte {
+	return []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		from, message.To, message.Subject, message.Body))
+}
@@ -0,0 +1,79 @@
+package oidc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ProviderConfig describes one OpenID Connect identity provider.
+type ProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// issuers ของ provider ที่รู้จัก ไม่ต้องตั้ง OIDC_<NAME>_ISSUER เอง
+var knownIssuers = map[string]string{
+	"google": "https://accounts.google.com",
+	"line":   "https://access.line.me",
+}
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+// ConfigsFromEnv reads the providers listed in OIDC_PROVIDERS (e.g.
+// "google,line"). Each provider is configured with OIDC_<NAME>_ISSUER,
+// OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET, OIDC_<NAME>_REDIRECT_URL
+// and optionally OIDC_<NAME>_SCOPES (space separated).
+func ConfigsFromEnv() ([]ProviderConfig, error) {
+	var configs []ProviderConfig
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		env := func(key string) string {
+			return os.Getenv("OIDC_" + strings.ToUpper(name) + "_" + key)
+		}
+
+		config := ProviderConfig{
+			Name:         name,
+			Issuer:       env("ISSUER"),
+			ClientID:     env("CLIENT_ID"),
+			ClientSecret: env("CLIENT_SECRET"),
+			RedirectURL:  env("REDIRECT_URL"),
+			Scopes:       strings.Fields(env("SCOPES")),
+		}
+		if config.Issuer == "" {
+			config.Issuer = knownIssuers[name]
+		}
+		if len(config.Scopes) == 0 {
+			config.Scopes = defaultScopes
+		}
+		if err := config.validate(); err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+func (c ProviderConfig) validate() error {
+	switch {
+	case c.Issuer == "":
+		return fmt.Errorf("oidc provider %q: issuer is required", c.Name)
+	case c.ClientID == "":
+		return fmt.Errorf("oidc provider %q: client id is required", c.Name)
+	case c.RedirectURL == "":
+		return fmt.Errorf("oidc provider %q: redirect url is required", c.Name)
+	}
+	for _, scope := range c.Scopes {
+		if scope == "openid" {
+			return nil
+		}
+	}
+	return fmt.Errorf("oidc provider %q: scopes must include openid", c.Name)
+}
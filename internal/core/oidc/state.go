@@ -0,0 +1,91 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+var ErrStateNotFound = errors.New("login state not found or expired")
+
+// Flow is what the login step remembers for the callback, keyed by the
+// state parameter.
+type Flow struct {
+	Provider string
+	Nonce    string
+	Verifier string
+}
+
+// NewFlow starts a login with fresh state, nonce and PKCE verifier.
+func NewFlow(provider string) (string, Flow, error) {
+	state, err := randomString()
+	if err != nil {
+		return "", Flow{}, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", Flow{}, err
+	}
+	return state, Flow{Provider: provider, Nonce: nonce, Verifier: oauth2.GenerateVerifier()}, nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// StateStore keeps pending logins between the redirect and the callback.
+// Like lockout.MemoryStore, the memory implementation only works for a single
+// instance.
+type StateStore interface {
+	Save(state string, flow Flow, ttl time.Duration) error
+	// Take returns the flow and removes it, so a state can only be used once.
+	Take(state string) (Flow, error)
+}
+
+type stateEntry struct {
+	flow      Flow
+	expiresAt time.Time
+}
+
+type MemoryStateStore struct {
+	mu      sync.Mutex
+	entries map[string]stateEntry
+}
+
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{entries: map[string]stateEntry{}}
+}
+
+func (s *MemoryStateStore) Save(state string, flow Flow, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.entries[state] = stateEntry{flow: flow, expiresAt: now.Add(ttl)}
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStateStore) Take(state string) (Flow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[state]
+	delete(s.entries, state)
+	if !ok || time.Now().After(entry.expiresAt) {
+		return Flow{}, ErrStateNotFound
+	}
+	return entry.flow, nil
+}
@@ -0,0 +1,259 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	stubKeyID        = "stub-key"
+	stubClientID     = "dev-client"
+	stubClientSecret = "dev-secret"
+	stubCodeTTL      = time.Minute
+)
+
+type stubGrant struct {
+	redirectURI   string
+	nonce         string
+	challenge     string
+	email         string
+	emailVerified bool
+	expiresAt     time.Time
+}
+
+// Stub is a minimal identity provider for local development and tests. It
+// signs in whoever is named by the login_hint query parameter without asking
+// for a password, so it must never be exposed in production.
+//
+// It serves discovery, /authorize, /token (checking the PKCE verifier) and
+// /jwks with RS256 signed ID tokens.
+type Stub struct {
+	issuer string
+	key    *rsa.PrivateKey
+	mux    *http.ServeMux
+
+	mu     sync.Mutex
+	grants map[string]stubGrant
+}
+
+func NewStub(issuer string) (*Stub, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	s := &Stub{issuer: strings.TrimSuffix(issuer, "/"), key: key, mux: http.NewServeMux(), grants: map[string]stubGrant{}}
+	s.mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	s.mux.HandleFunc("/authorize", s.authorize)
+	s.mux.HandleFunc("/token", s.token)
+	s.mux.HandleFunc("/jwks", s.jwks)
+	return s, nil
+}
+
+// ListenStub serves a stub on addr (e.g. ":9096") and returns the config of a
+// provider named "dev" that signs in through it.
+func ListenStub(addr string, redirectURL string) (ProviderConfig, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ProviderConfig{}, err
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return ProviderConfig{}, err
+	}
+	stub, err := NewStub("http://" + net.JoinHostPort(host, port))
+	if err != nil {
+		listener.Close()
+		return ProviderConfig{}, err
+	}
+
+	logrus.Warnf("stub identity provider listening on %s, do not enable in production", addr)
+	go func() {
+		if err := http.Serve(listener, stub); err != nil {
+			logrus.Errorf("stub identity provider stopped: %v", err)
+		}
+	}()
+	return stub.Config("dev", redirectURL), nil
+}
+
+// Config returns a provider config that points at the stub.
+func (s *Stub) Config(name string, redirectURL string) ProviderConfig {
+	return ProviderConfig{
+		Name:         name,
+		Issuer:       s.issuer,
+		ClientID:     stubClientID,
+		ClientSecret: stubClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       defaultScopes,
+	}
+}
+
+func (s *Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Stub) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.issuer,
+		"authorization_endpoint":                s.issuer + "/authorize",
+		"token_endpoint":                        s.issuer + "/token",
+		"jwks_uri":                              s.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize อนุมัติทันทีโดยใช้ login_hint เป็นอีเมล ใส่ email_verified=false เพื่อทดสอบอีเมลที่ยังไม่ยืนยัน
+func (s *Stub) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != stubClientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "only response_type=code with an S256 code_challenge is supported", http.StatusBadRequest)
+		return
+	}
+
+	email := query.Get("login_hint")
+	if email == "" {
+		email = "stub.user@example.com"
+	}
+	code, err := randomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	s.grants[code] = stubGrant{
+		redirectURI:   redirectURI.String(),
+		nonce:         query.Get("nonce"),
+		challenge:     query.Get("code_challenge"),
+		email:         email,
+		emailVerified: query.Get("email_verified") != "false",
+		expiresAt:     time.Now().Add(stubCodeTTL),
+	}
+	s.mu.Unlock()
+
+	values := redirectURI.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirectURI.RawQuery = values.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Stub) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != stubClientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(stubClientSecret)) != 1 {
+		tokenError(w, "invalid_client")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	grant, ok := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+
+	if r.PostForm.Get("grant_type") != "authorization_code" || !ok || time.Now().After(grant.expiresAt) ||
+		r.PostForm.Get("redirect_uri") != grant.redirectURI {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(challenge[:]) != grant.challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            s.issuer,
+		"sub":            stubSubject(grant.email),
+		"aud":            stubClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          grant.nonce,
+		"email":          grant.email,
+		"email_verified": grant.emailVerified,
+		"name":           grant.email,
+	})
+	idToken.Header["kid"] = stubKeyID
+	signed, err := idToken.SignedString(s.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	accessToken, _ := randomString()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+func (s *Stub) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": stubKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	})
+}
+
+// subject คงที่ต่ออีเมล เพื่อให้ login ซ้ำแล้วเจอ identity เดิม
+func stubSubject(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,208 @@
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const prefix = "enc"
+
+// Keyring holds the key encryption keys used for envelope encryption. Each
+// value gets its own random data key, which is sealed with the primary key and
+// stored next to the ciphertext. Older keys stay in the ring so values written
+// before a rotation can still be read.
+type Keyring struct {
+	primary  string
+	keys     map[string][]byte
+	indexKey []byte
+}
+
+// NewKeyring builds a keyring whose keys are 32 byte AES-256 keys.
+func NewKeyring(primary string, keys map[string][]byte, indexKey []byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key %q not found", primary)
+	}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %q must be 32 bytes", id)
+		}
+	}
+	if len(indexKey) < 32 {
+		return nil, errors.New("index key must be at least 32 bytes")
+	}
+	return &Keyring{primary: primary, keys: keys, indexKey: indexKey}, nil
+}
+
+// LoadKeyring reads keys from the environment:
+//
+//	PII_KEYS=v2:<base64 key>,v1:<base64 key>   the first key is the primary
+//	PII_INDEX_KEY=<base64 key>                 used for blind indexes only
+//
+// Both are required. For local development PII_DEV_KEYS=true fills in missing
+// ones with fixed keys, which are public and must never protect real data.
+func LoadKeyring() (*Keyring, error) {
+	rawKeys := os.Getenv("PII_KEYS")
+	rawIndexKey := os.Getenv("PII_INDEX_KEY")
+	devKeys := os.Getenv("PII_DEV_KEYS") == "true"
+	if rawKeys == "" {
+		if !devKeys {
+			return nil, errors.New("PII_KEYS is not set (PII_DEV_KEYS=true uses a development key)")
+		}
+		logrus.Warn("PII_KEYS is not set, using the development encryption key")
+		rawKeys = "dev:" + base64.StdEncoding.EncodeToString([]byte("dev-pii-key-do-not-use-in-prod!!"))
+	}
+	if rawIndexKey == "" {
+		if !devKeys {
+			return nil, errors.New("PII_INDEX_KEY is not set (PII_DEV_KEYS=true uses a development key)")
+		}
+		logrus.Warn("PII_INDEX_KEY is not set, using the development blind index key")
+		rawIndexKey = base64.StdEncoding.EncodeToString([]byte("dev-pii-index-key-not-for-prod!!"))
+	}
+
+	var primary string
+	keys := map[string][]byte{}
+	for _, entry := range strings.Split(rawKeys, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid PII_KEYS entry %q", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decode key %q: %w", id, err)
+		}
+		if primary == "" {
+			primary = id
+		}
+		keys[id] = key
+	}
+
+	indexKey, err := base64.StdEncoding.DecodeString(rawIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode PII_INDEX_KEY: %w", err)
+	}
+	return NewKeyring(primary, keys, indexKey)
+}
+
+// Encrypt returns "enc:<key id>:<sealed data key>:<ciphertext>".
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+
+	sealedKey, err := seal(k.keys[k.primary], dataKey, []byte(k.primary))
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataKey, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		prefix,
+		k.primary,
+		base64.RawStdEncoding.EncodeToString(sealedKey),
+		base64.RawStdEncoding.EncodeToString(ciphertext),
+	}, ":"), nil
+}
+
+// Decrypt reverses Encrypt with whichever key sealed the value. Values
+// without the "enc:" prefix were stored before encryption and are returned as is.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 || parts[0] != prefix {
+		return value, nil
+	}
+
+	key, ok := k.keys[parts[1]]
+	if !ok {
+		return "", fmt.Errorf("unknown encryption key %q", parts[1])
+	}
+	sealedKey, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := open(key, sealedKey, []byte(parts[1]))
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataKey, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a stored value is plaintext or sealed with a key other than the primary.
+func (k *Keyring) NeedsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+	parts := strings.SplitN(value, ":", 3)
+	return len(parts) < 3 || parts[0] != prefix || parts[1] != k.primary
+}
+
+// BlindIndex returns a keyed hash of the value so equal values can be looked
+// up without decrypting every row. Spaces and dashes are ignored.
+func (k *Keyring) BlindIndex(value string) string {
+	normalized := strings.NewReplacer(" ", "", "-", "").Replace(strings.ToLower(value))
+	if normalized == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,99 @@
+package pii
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var keyring *Keyring
+
+// SetKeyring sets the keyring used by String when reading and writing the database.
+func SetKeyring(k *Keyring) {
+	keyring = k
+}
+
+// Current returns the keyring set with SetKeyring.
+func Current() *Keyring {
+	return keyring
+}
+
+// String is a personal data column. It is plaintext in memory, encrypted in
+// the database, and masked when written as JSON or printed in logs.
+type String string
+
+// Plain returns the unmasked value.
+func (s String) Plain() string {
+	return string(s)
+}
+
+func (s String) String() string {
+	return Mask(string(s))
+}
+
+func (s String) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+func (s String) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Mask(string(s)))
+}
+
+func (s String) Value() (driver.Value, error) {
+	if s == "" {
+		return "", nil
+	}
+	if keyring == nil {
+		return nil, errors.New("pii keyring is not configured")
+	}
+	return keyring.Encrypt(string(s))
+}
+
+func (s *String) Scan(src interface{}) error {
+	var value string
+	switch v := src.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into pii.String", src)
+	}
+
+	if keyring == nil {
+		return errors.New("pii keyring is not configured")
+	}
+	plaintext, err := keyring.Decrypt(value)
+	if err != nil {
+		return err
+	}
+	*s = String(plaintext)
+	return nil
+}
+
+// Mask keeps the last 4 characters of longer values and hides the rest,
+// e.g. "0812345678" becomes "******5678".
+func Mask(value string) string {
+	runes := []rune(value)
+	if len(runes) == 0 {
+		return ""
+	}
+	visible := 0
+	if len(runes) >= 8 {
+		visible = 4
+	}
+	return strings.Repeat("*", len(runes)-visible) + string(runes[len(runes)-visible:])
+}
+
+// BlindIndex hashes value with the configured keyring, see Keyring.BlindIndex.
+func BlindIndex(value string) string {
+	if keyring == nil || value == "" {
+		return ""
+	}
+	return keyring.BlindIndex(value)
+}
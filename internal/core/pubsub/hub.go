@@ -0,0 +1,83 @@
+package pubsub
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Message struct {
+	Topic string      `json:"-"`
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"`
+}
+
+// Hub is an in-process publish/subscribe hub. Every subscriber of a topic gets
+// its own buffered channel; a subscriber whose buffer is full is dropped
+// instead of blocking the publisher, so one slow client cannot stall the rest.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*Subscription]struct{}
+	bufferSize  int
+}
+
+type Subscription struct {
+	C     <-chan Message
+	ch    chan Message
+	hub   *Hub
+	topic string
+	once  sync.Once
+}
+
+func NewHub(bufferSize int) *Hub {
+	return &Hub{
+		subscribers: map[string]map[*Subscription]struct{}{},
+		bufferSize:  bufferSize,
+	}
+}
+
+func (h *Hub) Subscribe(topic string) *Subscription {
+	ch := make(chan Message, h.bufferSize)
+	sub := &Subscription{C: ch, ch: ch, hub: h, topic: topic}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[topic] == nil {
+		h.subscribers[topic] = map[*Subscription]struct{}{}
+	}
+	h.subscribers[topic][sub] = struct{}{}
+	return sub
+}
+
+func (h *Hub) Publish(topic string, event string, data interface{}) {
+	msg := Message{Topic: topic, Event: event, Data: data}
+
+	h.mu.RLock()
+	var slow []*Subscription
+	for sub := range h.subscribers[topic] {
+		select {
+		case sub.ch <- msg:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range slow {
+		logrus.WithField("topic", topic).Warn("dropping slow subscriber")
+		sub.Close()
+	}
+}
+
+// Close unsubscribes and closes C. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.mu.Lock()
+		delete(s.hub.subscribers[s.topic], s)
+		if len(s.hub.subscribers[s.topic]) == 0 {
+			delete(s.hub.subscribers, s.topic)
+		}
+		s.hub.mu.Unlock()
+		close(s.ch)
+	})
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"food-delivery-workshop/internal/apperror"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Limiter struct {
+	store    Store
+	policies map[string]Policy
+}
+
+// NewLimiter creates a limiter with one policy per route group. Buckets are
+// namespaced by group, so a caller's login attempts do not use up their
+// promotion attempts.
+func NewLimiter(store Store, policies map[string]Policy) *Limiter {
+	return &Limiter{store: store, policies: policies}
+}
+
+func (l *Limiter) Policy(group string) (Policy, bool) {
+	policy, ok := l.policies[group]
+	return policy, ok
+}
+
+// Take uses one token of key's bucket in group.
+func (l *Limiter) Take(group, key string) (Result, error) {
+	policy, ok := l.policies[group]
+	if !ok {
+		return Result{}, fmt.Errorf("no rate limit policy for group %q", group)
+	}
+	return l.store.Take(group+":"+key, policy, time.Now())
+}
+
+// Allow takes a token for callers that do not send RateLimit headers, such as
+// the GraphQL and gRPC APIs, and returns a TooManyRequests error once the
+// bucket is empty. A failing store lets the request through.
+func (l *Limiter) Allow(ctx context.Context, group, key string) error {
+	result, err := l.Take(group, key)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("rate limit error: %v", err)
+		return nil
+	}
+	if !result.Allowed {
+		return Exceeded(result)
+	}
+	return nil
+}
+
+func Exceeded(result Result) error {
+	return apperror.TooManyRequests("RATE_LIMITED", "too many requests, please try again later", result.RetryAfter)
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy is a token bucket holding Limit tokens that refills Limit tokens
+// every Period. A burst can use the whole bucket at once; after that requests
+// are allowed at the refill rate.
+type Policy struct {
+	Limit  int
+	Period time.Duration
+}
+
+// Route groups with their own policy.
+const (
+	GroupAuth      = "auth"      // login, register, OTP and password reset
+	GroupPromotion = "promotion" // applying promotion codes
+)
+
+var DefaultPolicies = map[string]Policy{
+	GroupAuth:      {Limit: 10, Period: time.Minute},
+	GroupPromotion: {Limit: 5, Period: time.Minute},
+}
+
+// PoliciesFromEnv starts from DefaultPolicies and overrides a group with
+// RATE_LIMIT_<GROUP>, written as "<limit>/<period>" (e.g. "10/1m").
+func PoliciesFromEnv() (map[string]Policy, error) {
+	policies := map[string]Policy{}
+	for group, policy := range DefaultPolicies {
+		key := "RATE_LIMIT_" + strings.ToUpper(group)
+		if value := os.Getenv(key); value != "" {
+			parsed, err := ParsePolicy(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			policy = parsed
+		}
+		policies[group] = policy
+	}
+	return policies, nil
+}
+
+func ParsePolicy(value string) (Policy, error) {
+	rawLimit, rawPeriod, ok := strings.Cut(value, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("%q is not <limit>/<period>", value)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(rawLimit))
+	if err != nil || limit <= 0 {
+		return Policy{}, fmt.Errorf("limit %q must be a positive number", rawLimit)
+	}
+	period, err := time.ParseDuration(strings.TrimSpace(rawPeriod))
+	if err != nil || period <= 0 {
+		return Policy{}, fmt.Errorf("period %q must be a positive duration", rawPeriod)
+	}
+	return Policy{Limit: limit, Period: period}, nil
+}
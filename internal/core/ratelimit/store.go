@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Result is the state of a bucket after taking a token.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // จนกว่า bucket จะเต็มอีกครั้ง
+	RetryAfter time.Duration // จนกว่าจะมี token ถัดไป, 0 เมื่อ Allowed
+}
+
+// Store keeps token buckets. MemoryStore is enough for a single instance;
+// deployments with several instances need a shared implementation (e.g. Redis)
+// so the limit is not multiplied by the number of instances.
+type Store interface {
+	// Take refills the bucket for key and removes one token if there is one.
+	Take(key string, policy Policy, now time.Time) (Result, error)
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	period    time.Duration
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}}
+}
+
+func (s *MemoryStore) Take(key string, policy Policy, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit := float64(policy.Limit)
+	perToken := policy.Period / time.Duration(policy.Limit)
+
+	b := s.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: limit, updatedAt: now, period: policy.Period}
+		s.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.updatedAt); elapsed > 0 {
+		b.tokens = math.Min(limit, b.tokens+float64(elapsed)/float64(perToken))
+		b.updatedAt = now
+	}
+	b.period = policy.Period
+
+	result := Result{Limit: policy.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = time.Duration((limit - b.tokens) * float64(perToken))
+
+	s.sweep(now)
+	return result, nil
+}
+
+// sweep drops buckets that have refilled, which behave the same as new ones,
+// so the map does not grow with every IP seen.
+func (s *MemoryStore) sweep(now time.Time) {
+	if len(s.buckets) < 1024 {
+		return
+	}
+	for key, b := range s.buckets {
+		if now.Sub(b.updatedAt) >= b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type HTTPConfig struct {
+	URL     string // endpoint ของ SMS gateway ที่รับ JSON {to, from, message}
+	Token   string
+	From    string
+	Timeout time.Duration
+}
+
+type httpSender struct {
+	config HTTPConfig
+	client *http.Client
+}
+
+// NewHTTPSender posts messages to an SMS gateway with a bearer token.
+func NewHTTPSender(config HTTPConfig) SMSSender {
+	return &httpSender{config: config, client: &http.Client{Timeout: config.Timeout}}
+}
+
+func (s *httpSender) Send(phone string, message string) error {
+	body, err := json.Marshal(map[string]string{"to": phone, "from": s.config.From, "message": message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.config.Token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sms gateway returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+	"food-delivery-workshop/internal/core/pii"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SMSSender delivers a text message to a Thai mobile number.
+type SMSSender interface {
+	Send(phone string, message string) error
+}
+
+// FromEnv picks the sender from SMS_DRIVER. There is no default: http sends
+// through SMS_GATEWAY_URL, and log writes messages, OTP codes included, to the
+// log, so it must only be chosen for local development.
+func FromEnv() (SMSSender, error) {
+	switch driver := os.Getenv("SMS_DRIVER"); driver {
+	case "http":
+		url := os.Getenv("SMS_GATEWAY_URL")
+		if url == "" {
+			return nil, errors.New("SMS_GATEWAY_URL is not set")
+		}
+		return NewHTTPSender(HTTPConfig{
+			URL:     url,
+			Token:   os.Getenv("SMS_GATEWAY_TOKEN"),
+			From:    os.Getenv("SMS_FROM"),
+			Timeout: 10 * time.Second,
+		}), nil
+	case "log":
+		logrus.Warn("SMS_DRIVER=log writes text messages, including login codes, to the log; use it for local development only")
+		return NewLogSender(), nil
+	case "":
+		return nil, errors.New("SMS_DRIVER is not set (http, or log for local development)")
+	default:
+		return nil, fmt.Errorf("unknown SMS_DRIVER %q", driver)
+	}
+}
+
+type logSender struct{}
+
+// NewLogSender logs messages instead of sending them, for local development and tests.
+func NewLogSender() SMSSender {
+	return &logSender{}
+}
+
+func (s *logSender) Send(phone string, message string) error {
+	logrus.WithField("phone", pii.Mask(phone)).Infof("sms: %s", message)
+	return nil
+}
@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// RegisterDB adds a client span for every query run through db inside a
+// traced request, with the table, operation and parameterised SQL.
+func RegisterDB(db *gorm.DB) error {
+	return db.Use(&gormPlugin{})
+}
+
+type gormPlugin struct{}
+
+func (p *gormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("tracing:before_create", start("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", end),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", start("select")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", end),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", start("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", end),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", start("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", end),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", start("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", end),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", start("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", end),
+	)
+}
+
+func start(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+		_, span := Tracer().Start(ctx, "db."+operation, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation)))
+		db.InstanceSet(spanKey, &querySpan{span: span, operation: operation})
+	}
+}
+
+type querySpan struct {
+	span      trace.Span
+	operation string
+}
+
+func end(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	query, ok := value.(*querySpan)
+	if !ok {
+		return
+	}
+	span := query.span
+	defer span.End()
+
+	// ชื่อตารางรู้หลัง gorm parse model แล้ว จึงตั้งชื่อ span ตอนจบ
+	if table := db.Statement.Table; table != "" {
+		span.SetName("db." + query.operation + " " + table)
+		span.SetAttributes(semconv.DBCollectionName(table))
+	}
+	// SQL ที่ยังเป็น placeholder ไม่มีค่าจริงของ parameter จึงไม่มีข้อมูลส่วนตัวหลุดไป
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		RecordError(span, db.Error)
+	}
+}
@@ -0,0 +1,92 @@
+// Package tracing sets up OpenTelemetry tracing. Spans are exported with OTLP
+// or printed to stdout, and W3C trace context is read from incoming requests
+// so a trace continues across services.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"food-delivery-workshop/internal/apperror"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "food-delivery-workshop"
+
+// Setup installs the tracer provider chosen by OTEL_TRACES_EXPORTER: otlp
+// (configured with the standard OTEL_EXPORTER_OTLP_* variables), stdout for
+// local runs, or none (default) to record nothing. OTEL_SERVICE_NAME and
+// OTEL_TRACES_SAMPLER are honoured as well. The returned function flushes
+// spans that are still buffered and must be called on shutdown.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch name := os.Getenv("OTEL_TRACES_EXPORTER"); name {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown OTEL_TRACES_EXPORTER %q, want otlp, stdout or none", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName())))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func serviceName() string {
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		return name
+	}
+	return "food-delivery"
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts a span for a unit of work inside a request. Without a span in
+// ctx (e.g. background workers) it records nothing, so pollers do not flood the
+// exporter with tiny traces.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// RecordError adds err to span. Only internal errors mark the span as failed;
+// validation, not found and similar errors are the client's.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	if appErr := apperror.As(err); appErr == nil || appErr.Kind == apperror.KindInternal {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
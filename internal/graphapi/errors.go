@@ -0,0 +1,50 @@
+package graphapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"food-delivery-workshop/internal/apperror"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/sirupsen/logrus"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// presentError puts the same code and details the REST API returns into the
+// error extensions, and hides internal errors from the client.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	presented := graphql.DefaultErrorPresenter(ctx, err)
+
+	appErr := apperror.As(err)
+	if appErr != nil && appErr.Kind != apperror.KindInternal {
+		presented.Message = appErr.Message
+		presented.Extensions = map[string]interface{}{"code": appErr.Code}
+		if len(appErr.Details) > 0 {
+			presented.Extensions["details"] = appErr.Details
+		}
+		return presented
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		presented.Message = "the request took too long to complete"
+		presented.Extensions = map[string]interface{}{"code": "REQUEST_TIMEOUT"}
+		return presented
+	}
+
+	// ข้อผิดพลาดจากตัว query หรือ argument เป็นความผิดของ client แสดงได้ตามเดิม
+	var gqlErr *gqlerror.Error
+	if appErr == nil && errors.As(err, &gqlErr) {
+		return presented
+	}
+
+	logrus.WithContext(ctx).WithField("path", presented.Path.String()).Errorf("graphql request failed: %v", err)
+	presented.Message = "internal server error"
+	presented.Extensions = map[string]interface{}{"code": "INTERNAL_ERROR"}
+	return presented
+}
+
+// recoverPanic turns a resolver panic into an internal error, which
+// presentError logs.
+func recoverPanic(_ context.Context, p interface{}) error {
+	return apperror.Internal(fmt.Errorf("graphql resolver panic: %v", p))
+}
@@ -0,0 +1,78 @@
+package graphapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"food-delivery-workshop/internal/apperror"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/99designs/gqlgen/graphql/executor"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Request is a GraphQL request body.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func newExecutor(resolver *Resolver) *executor.Executor {
+	exec := executor.New(NewExecutableSchema(Config{
+		Resolvers:  resolver,
+		Complexity: complexity(),
+	}))
+	exec.Use(extension.Introspection{})
+	exec.Use(extension.FixedComplexityLimit(maxComplexity))
+	exec.Use(depthLimit(maxDepth))
+	exec.SetErrorPresenter(presentError)
+	exec.SetRecoverFunc(recoverPanic)
+	return exec
+}
+
+// NewHandler Run a GraphQL operation
+// @Summary GraphQL endpoint
+// @Description Query products, promotions, the cart and the signed in user, and change the cart, in one request. Errors are returned in the errors list with the same codes as the REST API in extensions.code. Operations are limited in depth and complexity.
+// @Tags graphql
+// @Accept  json
+// @Produce  json
+// @Param request body Request true "GraphQL request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 422 {object} map[string]interface{}
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /graphql [post]
+func NewHandler(resolver *Resolver) fiber.Handler {
+	exec := newExecutor(resolver)
+
+	return func(c *fiber.Ctx) error {
+		start := graphql.Now()
+		params := &graphql.RawParams{}
+		decoder := json.NewDecoder(bytes.NewReader(c.Body()))
+		decoder.UseNumber()
+		if err := decoder.Decode(params); err != nil {
+			return apperror.Validation("INVALID_BODY", "Invalid request body")
+		}
+		params.ReadTime = graphql.TraceTiming{Start: start, End: graphql.Now()}
+
+		// loader ต้องสร้างใหม่ทุก request เพื่อไม่ให้ cache ข้ามผู้ใช้
+		ctx := WithLoaders(c.UserContext(), NewLoaders(resolver.ProductService, resolver.PromotionService))
+		ctx = graphql.StartOperationTrace(ctx)
+
+		rc, errs := exec.CreateOperationContext(ctx, params)
+		if errs != nil {
+			status := fiber.StatusOK
+			if errcode.GetErrorKind(errs) == errcode.KindProtocol {
+				status = fiber.StatusUnprocessableEntity
+			}
+			return c.Status(status).JSON(exec.DispatchError(graphql.WithOperationContext(ctx, rc), errs))
+		}
+
+		responses, ctx := exec.DispatchOperation(ctx, rc)
+		return c.JSON(responses(ctx))
+	}
+}
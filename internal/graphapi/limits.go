@@ -0,0 +1,91 @@
+package graphapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	maxDepth      = 8
+	maxComplexity = 500
+	// listComplexity is the size assumed for lists when scoring a query, so
+	// fields under a list count more than fields under a single object.
+	listComplexity = 10
+)
+
+const errDepthLimit = "DEPTH_LIMIT_EXCEEDED"
+
+func init() {
+	errcode.RegisterErrorType(errDepthLimit, errcode.KindProtocol)
+}
+
+func complexity() ComplexityRoot {
+	var c ComplexityRoot
+	c.Query.Products = func(childComplexity int, _ *uint) int {
+		return 1 + listComplexity*childComplexity
+	}
+	c.Query.Promotions = func(childComplexity int) int {
+		return 1 + listComplexity*childComplexity
+	}
+	c.Cart.CartItems = func(childComplexity int) int {
+		return 1 + listComplexity*childComplexity
+	}
+	return c
+}
+
+// depthLimit rejects operations nested deeper than max fields, e.g. a
+// promotion -> product -> promotion -> ... loop.
+type depthLimit int
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = depthLimit(0)
+
+func (depthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (depthLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (max depthLimit) MutateOperationContext(_ context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if depth := selectionDepth(rc.Operation.SelectionSet); depth > int(max) {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, max)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+	return nil
+}
+
+// selectionDepth skips introspection fields so GraphQL tools can still load
+// the schema. Fragment cycles are already rejected during validation.
+func selectionDepth(selections ast.SelectionSet) int {
+	deepest := 0
+	for _, selection := range selections {
+		depth := 0
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			depth = 1 + selectionDepth(s.SelectionSet)
+		case *ast.InlineFragment:
+			depth = selectionDepth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				depth = selectionDepth(s.Definition.SelectionSet)
+			}
+		}
+		if depth > deepest {
+			deepest = depth
+		}
+	}
+	return deepest
+}
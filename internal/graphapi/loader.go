@@ -0,0 +1,80 @@
+package graphapi
+
+import (
+	"context"
+	"food-delivery-workshop/internal/models"
+	"food-delivery-workshop/internal/pkg/product"
+	"food-delivery-workshop/internal/pkg/promotion"
+
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// Loaders batch the lookups behind nested fields, so a cart with n items costs
+// one product query instead of n. They are created per request and cache
+// results for the rest of that request only.
+type Loaders struct {
+	Product            *dataloader.Loader[uint, *models.Product]
+	PromotionByProduct *dataloader.Loader[uint, *models.Promotion]
+}
+
+type loadersKey struct{}
+
+func NewLoaders(productService product.Service, promotionService promotion.Service) *Loaders {
+	return &Loaders{
+		Product:            dataloader.NewBatchedLoader(productBatch(productService)),
+		PromotionByProduct: dataloader.NewBatchedLoader(promotionBatch(promotionService)),
+	}
+}
+
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+func loadersFrom(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
+
+// productBatch resolves missing (e.g. deleted) products to nil.
+func productBatch(service product.Service) dataloader.BatchFunc[uint, *models.Product] {
+	return func(ctx context.Context, ids []uint) []*dataloader.Result[*models.Product] {
+		products, err := service.GetProductsByIDs(ctx, ids)
+		if err != nil {
+			return errorResults[*models.Product](len(ids), err)
+		}
+		byID := make(map[uint]*models.Product, len(products))
+		for i := range products {
+			byID[products[i].ID] = &products[i]
+		}
+		results := make([]*dataloader.Result[*models.Product], len(ids))
+		for i, id := range ids {
+			results[i] = &dataloader.Result[*models.Product]{Data: byID[id]}
+		}
+		return results
+	}
+}
+
+func promotionBatch(service promotion.Service) dataloader.BatchFunc[uint, *models.Promotion] {
+	return func(ctx context.Context, productIDs []uint) []*dataloader.Result[*models.Promotion] {
+		promotions, err := service.GetByProductIDs(ctx, productIDs)
+		if err != nil {
+			return errorResults[*models.Promotion](len(productIDs), err)
+		}
+		byProduct := make(map[uint]*models.Promotion, len(promotions))
+		for _, found := range promotions {
+			byProduct[found.ProductID] = found
+		}
+		results := make([]*dataloader.Result[*models.Promotion], len(productIDs))
+		for i, productID := range productIDs {
+			results[i] = &dataloader.Result[*models.Promotion]{Data: byProduct[productID]}
+		}
+		return results
+	}
+}
+
+func errorResults[V any](n int, err error) []*dataloader.Result[V] {
+	results := make([]*dataloader.Result[V], n)
+	for i := range results {
+		results[i] = &dataloader.Result[V]{Error: err}
+	}
+	return results
+}
@@ -0,0 +1,35 @@
+// Package graphapi serves the customer app's GraphQL API on top of the
+// product, promotion, cart and user services.
+package graphapi
+
+import (
+	"context"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/core/ratelimit"
+	"food-delivery-workshop/internal/pkg/cart"
+	"food-delivery-workshop/internal/pkg/product"
+	"food-delivery-workshop/internal/pkg/promotion"
+	"food-delivery-workshop/internal/pkg/user"
+)
+
+type Resolver struct {
+	ProductService   product.Service
+	PromotionService promotion.Service
+	CartService      cart.Service
+	UserService      user.Service
+	RateLimiter      *ratelimit.Limiter
+}
+
+// requireScope applies the same scope rules as the REST routes.
+func requireScope(ctx context.Context, scope string) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return apperror.Unauthorized("UNAUTHORIZED", "Unauthorized")
+	}
+	if !principal.Allows(scope) {
+		return apperror.Forbidden("INSUFFICIENT_SCOPE", "missing required scope").
+			WithDetails(map[string]interface{}{"scope": scope})
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package graphapi
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.60
+
+import (
+	"context"
+	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/core/ratelimit"
+	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/models"
+	"food-delivery-workshop/internal/pkg/cart"
+	"food-delivery-workshop/internal/validation"
+)
+
+// Product is the resolver for the product field.
+func (r *cartItemResolver) Product(ctx context.Context, obj *models.CartItem) (*models.Product, error) {
+	return loadersFrom(ctx).Product.Load(ctx, obj.ProductID)()
+}
+
+// AddCartItem is the resolver for the addCartItem field.
+func (r *mutationResolver) AddCartItem(ctx context.Context, productID uint, quantity int) (*models.Cart, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if quantity < 1 {
+		return nil, apperror.Validation("INVALID_QUANTITY", "quantity must be more than 0")
+	}
+
+	current, err := r.CartService.GetAllCart(ctx, &cart.GetAllRequests{UserID: principal.UserID})
+	if errors.Is(err, apperror.NotFound("CART_NOT_FOUND", "")) {
+		return r.CartService.Create(ctx, &cart.CreateRequest{
+			UserID:           principal.UserID,
+			CartItemRequests: []cart.CartItemRequest{{ProductID: productID, Quantity: uint(quantity)}},
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// ตะกร้ามีอยู่แล้ว: รวมจำนวนกับสินค้าเดิม แล้วส่งรายการทั้งหมดไปแทนที่
+	items := make([]cart.CartItemRequest, 0, len(current.CartItems)+1)
+	added := false
+	for _, item := range current.CartItems {
+		request := cart.CartItemRequest{ProductID: item.ProductID, Quantity: item.Quantity}
+		if item.ProductID == productID {
+			request.Quantity += uint(quantity)
+			added = true
+		}
+		items = append(items, request)
+	}
+	if !added {
+		items = append(items, cart.CartItemRequest{ProductID: productID, Quantity: uint(quantity)})
+	}
+	return r.CartService.Update(ctx, &cart.UpdateRequest{UserID: principal.UserID, CartItemRequests: items})
+}
+
+// UpdateCart is the resolver for the updateCart field.
+func (r *mutationResolver) UpdateCart(ctx context.Context, items []*cart.CartItemRequest) (*models.Cart, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &cart.UpdateRequest{UserID: principal.UserID, CartItemRequests: make([]cart.CartItemRequest, 0, len(items))}
+	for _, item := range items {
+		request.CartItemRequests = append(request.CartItemRequests, *item)
+	}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+	return r.CartService.Update(ctx, request)
+}
+
+// RemoveCartItem is the resolver for the removeCartItem field.
+func (r *mutationResolver) RemoveCartItem(ctx context.Context, productID uint) (*models.Cart, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &cart.RemoveItemRequest{UserID: principal.UserID, ProductID: productID}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+	return r.CartService.RemoveItem(ctx, request)
+}
+
+// ApplyPromotion is the resolver for the applyPromotion field.
+func (r *mutationResolver) ApplyPromotion(ctx context.Context, code string) (*models.Cart, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.RateLimiter.Allow(ctx, ratelimit.GroupPromotion, principal.Key()); err != nil {
+		return nil, err
+	}
+
+	request := &cart.PromotionRequest{UserID: principal.UserID, PromotionCode: code}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+	return r.CartService.ApplyPromotion(ctx, request)
+}
+
+// Promotion is the resolver for the promotion field.
+func (r *productResolver) Promotion(ctx context.Context, obj *models.Product) (*models.Promotion, error) {
+	return loadersFrom(ctx).PromotionByProduct.Load(ctx, obj.ID)()
+}
+
+// Product is the resolver for the product field.
+func (r *promotionResolver) Product(ctx context.Context, obj *models.Promotion) (*models.Product, error) {
+	return loadersFrom(ctx).Product.Load(ctx, obj.ProductID)()
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*models.User, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.UserService.GetUserByID(ctx, get.GetOne[uint]{ID: principal.UserID})
+}
+
+// Cart is the resolver for the cart field.
+func (r *queryResolver) Cart(ctx context.Context, latitude *float64, longitude *float64) (*models.Cart, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &cart.GetAllRequests{UserID: principal.UserID, Latitude: latitude, Longitude: longitude}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+	found, err := r.CartService.GetAllCart(ctx, request)
+	if errors.Is(err, apperror.NotFound("CART_NOT_FOUND", "")) {
+		return nil, nil
+	}
+	return found, err
+}
+
+// Products is the resolver for the products field.
+func (r *queryResolver) Products(ctx context.Context, restaurantID *uint) ([]*models.Product, error) {
+	if err := requireScope(ctx, auth.ScopeProductsRead); err != nil {
+		return nil, err
+	}
+
+	var products []models.Product
+	var err error
+	if restaurantID != nil {
+		products, err = r.ProductService.GetProductsByRestaurantID(ctx, &get.GetOne[uint]{ID: *restaurantID})
+	} else {
+		products, err = r.ProductService.GetAllProducts(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.Product, 0, len(products))
+	for i := range products {
+		result = append(result, &products[i])
+	}
+	return result, nil
+}
+
+// Product is the resolver for the product field.
+func (r *queryResolver) Product(ctx context.Context, id uint) (*models.Product, error) {
+	if err := requireScope(ctx, auth.ScopeProductsRead); err != nil {
+		return nil, err
+	}
+	return r.ProductService.GetProductByID(ctx, &get.GetOne[uint]{ID: id})
+}
+
+// Promotions is the resolver for the promotions field.
+func (r *queryResolver) Promotions(ctx context.Context) ([]*models.Promotion, error) {
+	if err := requireScope(ctx, auth.ScopePromotionsRead); err != nil {
+		return nil, err
+	}
+	return r.PromotionService.GetAll(ctx)
+}
+
+// Promotion is the resolver for the promotion field.
+func (r *queryResolver) Promotion(ctx context.Context, id uint) (*models.Promotion, error) {
+	if err := requireScope(ctx, auth.ScopePromotionsRead); err != nil {
+		return nil, err
+	}
+	return r.PromotionService.GetByID(ctx, &get.GetOne[uint]{ID: id})
+}
+
+// Phone is the resolver for the phone field.
+func (r *userResolver) Phone(ctx context.Context, obj *models.User) (string, error) {
+	return obj.Phone.String(), nil
+}
+
+// IDCard is the resolver for the idCard field.
+func (r *userResolver) IDCard(ctx context.Context, obj *models.User) (string, error) {
+	return obj.IDCard.String(), nil
+}
+
+// Address is the resolver for the address field.
+func (r *userResolver) Address(ctx context.Context, obj *models.User) (string, error) {
+	return obj.Address.String(), nil
+}
+
+// AddressDetails is the resolver for the addressDetails field.
+func (r *userResolver) AddressDetails(ctx context.Context, obj *models.User) (string, error) {
+	return obj.AddressDetails.String(), nil
+}
+
+// CartItem returns CartItemResolver implementation.
+func (r *Resolver) CartItem() CartItemResolver { return &cartItemResolver{r} }
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Product returns ProductResolver implementation.
+func (r *Resolver) Product() ProductResolver { return &productResolver{r} }
+
+// Promotion returns PromotionResolver implementation.
+func (r *Resolver) Promotion() PromotionResolver { return &promotionResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type cartItemResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
+type productResolver struct{ *Resolver }
+type promotionResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
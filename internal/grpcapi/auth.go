@@ -0,0 +1,83 @@
+package grpcapi
+
+import (
+	"context"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/pkg/apikey"
+	"strings"
+
+	pb "food-delivery-workshop/api/gen/fooddelivery/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// publicMethods can be called without credentials.
+var publicMethods = map[string]bool{
+	pb.UserService_Register_FullMethodName: true,
+	pb.UserService_Login_FullMethodName:    true,
+}
+
+// methodScopes are the scopes callers need, matching the REST routes.
+// Methods that act on the signed in user reject API keys in the handler.
+var methodScopes = map[string]string{
+	pb.ProductService_CreateProduct_FullMethodName:     auth.ScopeProductsWrite,
+	pb.ProductService_UpdateProduct_FullMethodName:     auth.ScopeProductsWrite,
+	pb.ProductService_DeleteProduct_FullMethodName:     auth.ScopeProductsWrite,
+	pb.ProductService_GetProduct_FullMethodName:        auth.ScopeProductsRead,
+	pb.ProductService_ListProducts_FullMethodName:      auth.ScopeProductsRead,
+	pb.PromotionService_CreatePromotion_FullMethodName: auth.ScopePromotionsWrite,
+	pb.PromotionService_UpdatePromotion_FullMethodName: auth.ScopePromotionsWrite,
+	pb.PromotionService_DeletePromotion_FullMethodName: auth.ScopePromotionsWrite,
+	pb.PromotionService_GetPromotion_FullMethodName:    auth.ScopePromotionsRead,
+	pb.PromotionService_ListPromotions_FullMethodName:  auth.ScopePromotionsRead,
+}
+
+// authInterceptor reads a bearer token from the authorization metadata, or an
+// API key from x-api-key, and puts the principal in the context.
+func authInterceptor(apiKeyService apikey.Service) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		principal, err := authenticate(ctx, apiKeyService)
+		if err != nil {
+			return nil, err
+		}
+		if scope, ok := methodScopes[info.FullMethod]; ok && !principal.Allows(scope) {
+			return nil, apperror.Forbidden("INSUFFICIENT_SCOPE", "missing required scope").
+				WithDetails(map[string]interface{}{"scope": scope})
+		}
+		return handler(auth.NewContext(ctx, principal), req)
+	}
+}
+
+func authenticate(ctx context.Context, apiKeyService apikey.Service) (*auth.Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if key := firstValue(md, strings.ToLower(auth.HeaderAPIKey)); key != "" {
+		apiKey, err := apiKeyService.Authenticate(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return &auth.Principal{APIKeyID: apiKey.ID, Scopes: apiKey.Scopes}, nil
+	}
+
+	token, ok := strings.CutPrefix(firstValue(md, "authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil, apperror.Unauthorized("UNAUTHORIZED", "Unauthorized")
+	}
+	claims, err := auth.ParseJWT(token)
+	if err != nil {
+		return nil, apperror.Unauthorized("UNAUTHORIZED", "Unauthorized")
+	}
+	return claims.Principal(), nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
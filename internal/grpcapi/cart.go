@@ -0,0 +1,127 @@
+package grpcapi
+
+import (
+	"context"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/core/ratelimit"
+	"food-delivery-workshop/internal/pkg/cart"
+	"food-delivery-workshop/internal/validation"
+	"time"
+
+	pb "food-delivery-workshop/api/gen/fooddelivery/v1"
+)
+
+type cartServer struct {
+	pb.UnimplementedCartServiceServer
+	service cart.Service
+	limiter *ratelimit.Limiter
+}
+
+func (s *cartServer) CreateCart(ctx context.Context, in *pb.CreateCartRequest) (*pb.CreateCartResponse, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &cart.CreateRequest{UserID: principal.UserID, CartItemRequests: toCartItems(in.GetCartItems())}
+	if in.ScheduledFor != nil {
+		scheduledFor := in.GetScheduledFor().AsTime().In(time.Local)
+		request.ScheduledFor = &scheduledFor
+	}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	created, err := s.service.Create(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateCartResponse{Cart: toCart(created)}, nil
+}
+
+func (s *cartServer) UpdateCart(ctx context.Context, in *pb.UpdateCartRequest) (*pb.UpdateCartResponse, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &cart.UpdateRequest{UserID: principal.UserID, CartItemRequests: toCartItems(in.GetCartItems())}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.service.Update(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateCartResponse{Cart: toCart(updated)}, nil
+}
+
+func (s *cartServer) ApplyPromotion(ctx context.Context, in *pb.ApplyPromotionRequest) (*pb.ApplyPromotionResponse, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.limiter.Allow(ctx, ratelimit.GroupPromotion, principal.Key()); err != nil {
+		return nil, err
+	}
+
+	request := &cart.PromotionRequest{UserID: principal.UserID, PromotionCode: in.GetPromotionCode()}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.service.ApplyPromotion(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ApplyPromotionResponse{Cart: toCart(updated)}, nil
+}
+
+func (s *cartServer) RemoveCartItem(ctx context.Context, in *pb.RemoveCartItemRequest) (*pb.RemoveCartItemResponse, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &cart.RemoveItemRequest{UserID: principal.UserID, ProductID: uint(in.GetProductId())}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.service.RemoveItem(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RemoveCartItemResponse{Cart: toCart(updated)}, nil
+}
+
+func (s *cartServer) GetCart(ctx context.Context, in *pb.GetCartRequest) (*pb.GetCartResponse, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &cart.GetAllRequests{UserID: principal.UserID, Latitude: in.Latitude, Longitude: in.Longitude}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	found, err := s.service.GetAllCart(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetCartResponse{Cart: toCart(found)}, nil
+}
+
+func toCartItems(items []*pb.CartItemInput) []cart.CartItemRequest {
+	requests := make([]cart.CartItemRequest, 0, len(items))
+	for _, item := range items {
+		requests = append(requests, cart.CartItemRequest{
+			ProductID: uint(item.GetProductId()),
+			Quantity:  uint(item.GetQuantity()),
+		})
+	}
+	return requests
+}
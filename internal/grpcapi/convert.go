@@ -0,0 +1,121 @@
+package grpcapi
+
+import (
+	"food-delivery-workshop/internal/models"
+	"time"
+
+	pb "food-delivery-workshop/api/gen/fooddelivery/v1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProduct(product *models.Product) *pb.Product {
+	if product == nil {
+		return nil
+	}
+	return &pb.Product{
+		Id:           uint64(product.ID),
+		Name:         product.Name,
+		Description:  product.Description,
+		Price:        product.Price,
+		RestaurantId: uint64Ptr(product.RestaurantID),
+		PrepMinutes:  uint32(product.PrepMinutes),
+		CreatedAt:    timestamppb.New(product.CreatedAt),
+		UpdatedAt:    timestamppb.New(product.UpdatedAt),
+	}
+}
+
+func toPromotion(promotion *models.Promotion) *pb.Promotion {
+	if promotion == nil {
+		return nil
+	}
+	return &pb.Promotion{
+		Id:        uint64(promotion.ID),
+		Code:      promotion.Code,
+		Discount:  promotion.Discount,
+		ProductId: uint64(promotion.ProductID),
+		Product:   toProduct(promotion.Product),
+		CreatedAt: timestamppb.New(promotion.CreatedAt),
+		UpdatedAt: timestamppb.New(promotion.UpdatedAt),
+	}
+}
+
+func toCart(cart *models.Cart) *pb.Cart {
+	if cart == nil {
+		return nil
+	}
+	items := make([]*pb.CartItem, 0, len(cart.CartItems))
+	for _, item := range cart.CartItems {
+		items = append(items, &pb.CartItem{
+			Id:         uint64(item.ID),
+			ProductId:  uint64(item.ProductID),
+			Quantity:   uint32(item.Quantity),
+			Price:      item.Price,
+			TotalPrice: item.TotalPrice,
+			Product:    toProduct(item.Product),
+		})
+	}
+	return &pb.Cart{
+		Id:          uint64(cart.ID),
+		UserId:      uint64(cart.UserID),
+		PromotionId: uint64Ptr(cart.PromotionID),
+		Promotion:   toPromotion(cart.Promotion),
+		CartItems:   items,
+		SubTotal:    cart.SubTotal,
+		Total:       cart.Total,
+		Discount:    cart.Discount,
+		Eta:         toETA(cart.ETA),
+	}
+}
+
+func toETA(eta *models.ETA) *pb.ETA {
+	if eta == nil {
+		return nil
+	}
+	return &pb.ETA{
+		MinMinutes: int32(eta.MinMinutes),
+		MaxMinutes: int32(eta.MaxMinutes),
+		EarliestAt: timestampPtr(eta.EarliestAt),
+		LatestAt:   timestampPtr(eta.LatestAt),
+	}
+}
+
+// toUser returns PII masked, the same as the JSON API.
+func toUser(user *models.User) *pb.User {
+	return &pb.User{
+		Id:              uint64(user.ID),
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		Email:           user.Email,
+		EmailVerifiedAt: timestampPtr(user.EmailVerifiedAt),
+		Phone:           user.Phone.String(),
+		IdCard:          user.IDCard.String(),
+		Address:         user.Address.String(),
+		AddressDetails:  user.AddressDetails.String(),
+		Roles:           user.Roles,
+		CreatedAt:       timestamppb.New(user.CreatedAt),
+	}
+}
+
+func timestampPtr(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func uint64Ptr(id *uint) *uint64 {
+	if id == nil {
+		return nil
+	}
+	value := uint64(*id)
+	return &value
+}
+
+func uintPtr(id *uint64) *uint {
+	if id == nil {
+		return nil
+	}
+	value := uint(*id)
+	return &value
+}
@@ -0,0 +1,104 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/validation"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const errorDomain = "food-delivery-workshop"
+
+// errorInterceptor is the gRPC counterpart of middleware.ErrorHandler: it
+// turns service errors into status errors with the same error codes.
+func errorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, toStatus(ctx, info.FullMethod, err)
+	}
+	return resp, nil
+}
+
+var kindCodes = map[apperror.Kind]codes.Code{
+	apperror.KindValidation:      codes.InvalidArgument,
+	apperror.KindUnauthorized:    codes.Unauthenticated,
+	apperror.KindForbidden:       codes.PermissionDenied,
+	apperror.KindNotFound:        codes.NotFound,
+	apperror.KindConflict:        codes.FailedPrecondition,
+	apperror.KindTooManyRequests: codes.ResourceExhausted,
+}
+
+func toStatus(ctx context.Context, method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	appErr := apperror.As(err)
+	code, known := codes.Internal, false
+	if appErr != nil {
+		code, known = kindCodes[appErr.Kind]
+	}
+	switch {
+	case known:
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "the request took too long to complete")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "the request was cancelled")
+	default:
+		logrus.WithContext(ctx).WithField("method", method).Errorf("grpc request failed: %v", err)
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	st := status.New(code, appErr.Message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   appErr.Code,
+		Domain:   errorDomain,
+		Metadata: stringMetadata(appErr.Details),
+	}}
+	if fields, ok := appErr.Details["fields"].([]validation.FieldError); ok {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+		for _, field := range fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message})
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	if retryAfter, ok := appErr.Details["retry_after"].(int); ok {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Duration(retryAfter) * time.Second)})
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// stringMetadata flattens error details into ErrorInfo metadata, which only
+// holds strings. Values that are not strings are JSON encoded.
+func stringMetadata(details map[string]interface{}) map[string]string {
+	if len(details) == 0 {
+		return nil
+	}
+	metadata := make(map[string]string, len(details))
+	for key, value := range details {
+		if s, ok := value.(string); ok {
+			metadata[key] = s
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(value))
+		}
+		metadata[key] = string(encoded)
+	}
+	return metadata
+}
@@ -0,0 +1,80 @@
+package grpcapi
+
+import (
+	"context"
+	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/pkg/product"
+	"food-delivery-workshop/internal/validation"
+
+	pb "food-delivery-workshop/api/gen/fooddelivery/v1"
+)
+
+type productServer struct {
+	pb.UnimplementedProductServiceServer
+	service product.Service
+}
+
+func (s *productServer) CreateProduct(ctx context.Context, in *pb.CreateProductRequest) (*pb.CreateProductResponse, error) {
+	request := &product.CreateRequest{Request: product.Request{
+		Name:         in.GetName(),
+		Description:  in.GetDescription(),
+		Price:        in.GetPrice(),
+		RestaurantID: uintPtr(in.RestaurantId),
+		PrepMinutes:  uint(in.GetPrepMinutes()),
+	}}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	created, err := s.service.Create(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateProductResponse{Product: toProduct(created)}, nil
+}
+
+func (s *productServer) UpdateProduct(ctx context.Context, in *pb.UpdateProductRequest) (*pb.UpdateProductResponse, error) {
+	request := &product.UpdateRequest{ID: uint(in.GetId()), Request: product.Request{
+		Name:         in.GetName(),
+		Description:  in.GetDescription(),
+		Price:        in.GetPrice(),
+		RestaurantID: uintPtr(in.RestaurantId),
+		PrepMinutes:  uint(in.GetPrepMinutes()),
+	}}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.service.Update(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateProductResponse{Product: toProduct(updated)}, nil
+}
+
+func (s *productServer) DeleteProduct(ctx context.Context, in *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	if err := s.service.Delete(ctx, &get.GetOne[uint]{ID: uint(in.GetId())}); err != nil {
+		return nil, err
+	}
+	return &pb.DeleteProductResponse{}, nil
+}
+
+func (s *productServer) GetProduct(ctx context.Context, in *pb.GetProductRequest) (*pb.GetProductResponse, error) {
+	found, err := s.service.GetProductByID(ctx, &get.GetOne[uint]{ID: uint(in.GetId())})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetProductResponse{Product: toProduct(found)}, nil
+}
+
+func (s *productServer) ListProducts(ctx context.Context, _ *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
+	products, err := s.service.GetAllProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	response := &pb.ListProductsResponse{Products: make([]*pb.Product, 0, len(products))}
+	for i := range products {
+		response.Products = append(response.Products, toProduct(&products[i]))
+	}
+	return response, nil
+}
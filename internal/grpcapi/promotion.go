@@ -0,0 +1,76 @@
+package grpcapi
+
+import (
+	"context"
+	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/pkg/promotion"
+	"food-delivery-workshop/internal/validation"
+
+	pb "food-delivery-workshop/api/gen/fooddelivery/v1"
+)
+
+type promotionServer struct {
+	pb.UnimplementedPromotionServiceServer
+	service promotion.Service
+}
+
+func (s *promotionServer) CreatePromotion(ctx context.Context, in *pb.CreatePromotionRequest) (*pb.CreatePromotionResponse, error) {
+	request := &promotion.CreateRequest{Request: promotion.Request{
+		Code:      in.GetCode(),
+		Discount:  in.GetDiscount(),
+		ProductID: uint(in.GetProductId()),
+	}}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	created, err := s.service.Create(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreatePromotionResponse{Promotion: toPromotion(created)}, nil
+}
+
+func (s *promotionServer) UpdatePromotion(ctx context.Context, in *pb.UpdatePromotionRequest) (*pb.UpdatePromotionResponse, error) {
+	request := &promotion.UpdateRequest{ID: uint(in.GetId()), Request: promotion.Request{
+		Code:      in.GetCode(),
+		Discount:  in.GetDiscount(),
+		ProductID: uint(in.GetProductId()),
+	}}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.service.Update(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdatePromotionResponse{Promotion: toPromotion(updated)}, nil
+}
+
+func (s *promotionServer) DeletePromotion(ctx context.Context, in *pb.DeletePromotionRequest) (*pb.DeletePromotionResponse, error) {
+	if err := s.service.Delete(ctx, &get.GetOne[uint]{ID: uint(in.GetId())}); err != nil {
+		return nil, err
+	}
+	return &pb.DeletePromotionResponse{}, nil
+}
+
+func (s *promotionServer) GetPromotion(ctx context.Context, in *pb.GetPromotionRequest) (*pb.GetPromotionResponse, error) {
+	found, err := s.service.GetByID(ctx, &get.GetOne[uint]{ID: uint(in.GetId())})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetPromotionResponse{Promotion: toPromotion(found)}, nil
+}
+
+func (s *promotionServer) ListPromotions(ctx context.Context, _ *pb.ListPromotionsRequest) (*pb.ListPromotionsResponse, error) {
+	promotions, err := s.service.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	response := &pb.ListPromotionsResponse{Promotions: make([]*pb.Promotion, 0, len(promotions))}
+	for _, found := range promotions {
+		response.Promotions = append(response.Promotions, toPromotion(found))
+	}
+	return response, nil
+}
@@ -0,0 +1,26 @@
+package grpcapi
+
+import (
+	"context"
+	"food-delivery-workshop/internal/core/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const metadataRequestID = "x-request-id"
+
+// requestIDInterceptor does for gRPC what the REST RequestID middleware does:
+// it keeps or generates x-request-id, sends it back in the response header and
+// puts it in the context for logging.
+func requestIDInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var incoming string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(metadataRequestID); len(values) > 0 {
+			incoming = values[0]
+		}
+	}
+	requestID := logging.RequestID(incoming)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, requestID))
+	return handler(logging.NewContext(ctx, requestID), req)
+}
@@ -0,0 +1,47 @@
+// Package grpcapi serves the product, promotion, cart and user services over
+// gRPC, next to the REST API and backed by the same services.
+package grpcapi
+
+import (
+	"food-delivery-workshop/internal/core/ratelimit"
+	"food-delivery-workshop/internal/pkg/apikey"
+	"food-delivery-workshop/internal/pkg/cart"
+	"food-delivery-workshop/internal/pkg/product"
+	"food-delivery-workshop/internal/pkg/promotion"
+	"food-delivery-workshop/internal/pkg/user"
+
+	pb "food-delivery-workshop/api/gen/fooddelivery/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+type Services struct {
+	Product   product.Service
+	Promotion promotion.Service
+	Cart      cart.Service
+	User      user.Service
+	APIKey    apikey.Service
+	// shared with the REST routes so a caller has one limit across APIs
+	RateLimiter *ratelimit.Limiter
+}
+
+// NewServer registers the services. Reflection lets tools like grpcurl list
+// the methods and should only be turned on for local debugging.
+func NewServer(services Services, enableReflection bool) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		requestIDInterceptor,
+		errorInterceptor,
+		authInterceptor(services.APIKey),
+	))
+
+	pb.RegisterProductServiceServer(server, &productServer{service: services.Product})
+	pb.RegisterPromotionServiceServer(server, &promotionServer{service: services.Promotion})
+	pb.RegisterCartServiceServer(server, &cartServer{service: services.Cart, limiter: services.RateLimiter})
+	pb.RegisterUserServiceServer(server, &userServer{service: services.User, limiter: services.RateLimiter})
+
+	if enableReflection {
+		reflection.Register(server)
+	}
+	return server
+}
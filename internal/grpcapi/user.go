@@ -0,0 +1,128 @@
+package grpcapi
+
+import (
+	"context"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/core/ratelimit"
+	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/pkg/user"
+	"food-delivery-workshop/internal/validation"
+	"net"
+
+	pb "food-delivery-workshop/api/gen/fooddelivery/v1"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	service user.Service
+	limiter *ratelimit.Limiter
+}
+
+func (s *userServer) Register(ctx context.Context, in *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	if err := s.limiter.Allow(ctx, ratelimit.GroupAuth, "ip:"+peerIP(ctx)); err != nil {
+		return nil, err
+	}
+
+	request := &user.CreateRequest{Request: user.Request{
+		FirstName:      in.GetFirstName(),
+		LastName:       in.GetLastName(),
+		Email:          in.GetEmail(),
+		Password:       in.GetPassword(),
+		Phone:          in.GetPhone(),
+		IDCard:         in.GetIdCard(),
+		Address:        in.GetAddress(),
+		AddressDetails: in.GetAddressDetails(),
+	}}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	created, err := s.service.Create(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RegisterResponse{User: toUser(created)}, nil
+}
+
+func (s *userServer) Login(ctx context.Context, in *pb.LoginRequest) (*pb.LoginResponse, error) {
+	ip := peerIP(ctx)
+	// ใช้ key เดียวกับ REST เพื่อให้ผู้เรียนมี limit เดียวทั้งสอง API
+	if err := s.limiter.Allow(ctx, ratelimit.GroupAuth, "ip:"+ip); err != nil {
+		return nil, err
+	}
+
+	request := &user.LoginRequest{Email: in.GetEmail(), Password: in.GetPassword()}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	// IP และ user agent ใช้กับการล็อกบัญชีเหมือน REST
+	request.IP = ip
+	md, _ := metadata.FromIncomingContext(ctx)
+	request.UserAgent = firstValue(md, "user-agent")
+
+	found, err := s.service.Login(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := auth.GenerateJWT(found.ID, found.Roles)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LoginResponse{AccessToken: token, User: toUser(found)}, nil
+}
+
+func (s *userServer) GetMe(ctx context.Context, _ *pb.GetMeRequest) (*pb.GetMeResponse, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := s.service.GetUserByID(ctx, get.GetOne[uint]{ID: principal.UserID})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetMeResponse{User: toUser(found)}, nil
+}
+
+func (s *userServer) UpdateProfile(ctx context.Context, in *pb.UpdateProfileRequest) (*pb.UpdateProfileResponse, error) {
+	principal, err := auth.UserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &user.UpdateProfileRequest{
+		ID:             principal.UserID,
+		FirstName:      in.FirstName,
+		LastName:       in.LastName,
+		Phone:          in.Phone,
+		Address:        in.Address,
+		AddressDetails: in.AddressDetails,
+	}
+	if err := validation.Struct(request); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.service.UpdateProfile(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateProfileResponse{User: toUser(updated)}, nil
+}
+
+// peerIP is the caller's address without the port.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	ip := p.Addr.String()
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		return host
+	}
+	return ip
+}
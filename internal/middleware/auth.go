@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/pkg/apikey"
+
+	"github.com/gofiber/fiber/v2"
+	jwtware "github.com/gofiber/jwt/v3"
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// Authenticate accepts a user JWT in the Authorization header or a service
+// API key in the X-API-Key header and stores the resulting auth.Principal.
+// tokenLookup is passed to the JWT middleware (empty means the header only).
+func Authenticate(apiKeyService apikey.Service, tokenLookup string) fiber.Handler {
+	jwtAuth := jwtware.New(jwtware.Config{
+		SigningKey:  []byte(auth.SecretKey),
+		Claims:      &auth.Claims{},
+		TokenLookup: tokenLookup,
+		AuthScheme:  "Bearer",
+		ErrorHandler: func(c *fiber.Ctx, _ error) error {
+			return apperror.Unauthorized("UNAUTHORIZED", "Unauthorized")
+		},
+		SuccessHandler: setUserPrincipal,
+	})
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(auth.HeaderAPIKey)
+		if key == "" {
+			return jwtAuth(c)
+		}
+
+		apiKey, err := apiKeyService.Authenticate(c.UserContext(), key)
+		if err != nil {
+			return err
+		}
+		auth.SetPrincipal(c, &auth.Principal{APIKeyID: apiKey.ID, Scopes: apiKey.Scopes})
+		return c.Next()
+	}
+}
+
+func setUserPrincipal(c *fiber.Ctx) error {
+	token := c.Locals("user").(*jwt.Token)
+	claims, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		return apperror.Unauthorized("INVALID_TOKEN", "Invalid user token")
+	}
+	auth.SetPrincipal(c, claims.Principal())
+	return c.Next()
+}
+
+// RequireScope rejects principals without scope. API keys need it granted,
+// users need a role that grants it.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := auth.FromContext(c.UserContext())
+		if !ok {
+			return apperror.Unauthorized("UNAUTHORIZED", "Unauthorized")
+		}
+		if !principal.Allows(scope) {
+			return apperror.Forbidden("INSUFFICIENT_SCOPE", "missing required scope").
+				WithDetails(map[string]interface{}{"scope": scope})
+		}
+		return c.Next()
+	}
+}
+
+// callerKey identifies who is calling: the principal, or the client IP for
+// requests without one.
+func callerKey(c *fiber.Ctx) string {
+	if principal, ok := auth.FromContext(c.UserContext()); ok {
+		return principal.Key()
+	}
+	return "ip:" + c.IP()
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ErrorResponse struct {
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	RequestID string                 `json:"request_id"`
+}
+
+// ErrorHandler maps errors returned by handlers to a consistent JSON body.
+// *apperror.Error keeps its code and status, *fiber.Error keeps its status,
+// a request that ran out of time is a 504, and anything else is logged and
+// reported as a 500 without internals.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	requestID, _ := c.Locals("requestid").(string)
+	status := http.StatusInternalServerError
+	response := &ErrorResponse{
+		Code:      "INTERNAL_ERROR",
+		Message:   "internal server error",
+		RequestID: requestID,
+	}
+
+	var fiberErr *fiber.Error
+	if appErr := apperror.As(err); appErr != nil {
+		status = appErr.Status()
+		response.Code = appErr.Code
+		response.Message = appErr.Message
+		response.Details = appErr.Details
+		if retryAfter, ok := appErr.Details["retry_after"].(int); ok {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		}
+	} else if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+		response.Code = statusCode(fiberErr.Code)
+		response.Message = fiberErr.Message
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		status = http.StatusGatewayTimeout
+		response.Code = "REQUEST_TIMEOUT"
+		response.Message = "the request took too long to complete"
+	}
+
+	if status >= http.StatusInternalServerError {
+		logrus.WithContext(c.UserContext()).Errorf("request failed: %v", err)
+	}
+
+	return c.Status(status).JSON(response)
+}
+
+// statusCode turns 404 into NOT_FOUND, 405 into METHOD_NOT_ALLOWED and so on.
+func statusCode(status int) string {
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
+
+// handleNext runs the rest of the chain and writes the error response right
+// away, so the calling middleware sees the status the client gets.
+func handleNext(c *fiber.Ctx) error {
+	if err := c.Next(); err != nil {
+		return c.App().ErrorHandler(c, err)
+	}
+	return nil
+}
+
+// routePattern is the pattern of the route that handled the request, or
+// "unmatched" while c.Route() is still own, the route of the calling
+// middleware, so random paths do not each get their own label.
+func routePattern(c *fiber.Ctx, own *fiber.Route) string {
+	if c.Route() == own {
+		return "unmatched"
+	}
+	return c.Route().Path
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/idempotency"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotentReplayed  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyLease          = time.Minute // นานกว่า RequestTimeout เพื่อให้ request แรกทำงานเสร็จก่อน
+	idempotencyInProgressWait = 1           // วินาทีใน Retry-After ระหว่างที่ request แรกยังไม่เสร็จ
+)
+
+// Idempotency replays the stored response when a request is retried with the
+// same Idempotency-Key header, so a client on a flaky network can safely retry
+// a mutation. Keys are scoped to the caller and kept for ttl. Reusing a key
+// with a different request is a 409, and so is a retry that arrives while the
+// first request is still running. Server errors are not stored, so the client
+// can retry those. Requests without the header are passed through.
+func Idempotency(store idempotency.Store, ttl time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return apperror.Validation("INVALID_IDEMPOTENCY_KEY", "Idempotency-Key must be at most 255 characters")
+		}
+
+		storeKey := callerKey(c) + ":" + key
+		fingerprint := requestFingerprint(c)
+		existing, err := store.Reserve(storeKey, fingerprint, idempotencyLease)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if existing.Fingerprint != fingerprint {
+				return apperror.Conflict("IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request")
+			}
+			if !existing.Done {
+				return apperror.Conflict("IDEMPOTENCY_REQUEST_IN_PROGRESS", "a request with this Idempotency-Key is still in progress").
+					WithDetails(map[string]interface{}{"retry_after": idempotencyInProgressWait})
+			}
+			c.Set(HeaderIdempotentReplayed, "true")
+			c.Set(fiber.HeaderContentType, existing.ContentType)
+			return c.Status(existing.Status).Send(existing.Body)
+		}
+
+		// เก็บ response เดียวกับที่ client ได้รับ รวมถึง error response
+		if err := handleNext(c); err != nil {
+			releaseIdempotencyKey(c, store, storeKey)
+			return err
+		}
+
+		response := c.Response()
+		if response.StatusCode() >= http.StatusInternalServerError {
+			releaseIdempotencyKey(c, store, storeKey)
+			return nil
+		}
+		err = store.Complete(storeKey, idempotency.Entry{
+			Fingerprint: fingerprint,
+			Status:      response.StatusCode(),
+			ContentType: string(response.Header.ContentType()),
+			Body:        append([]byte(nil), response.Body()...),
+		}, ttl)
+		if err != nil {
+			logrus.WithContext(c.UserContext()).Errorf("store idempotent response error: %v", err)
+		}
+		return nil
+	}
+}
+
+// requestFingerprint identifies what was asked: method, path and body.
+func requestFingerprint(c *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Method() + " " + c.OriginalURL() + "\n"))
+	hash.Write(c.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func releaseIdempotencyKey(c *fiber.Ctx, store idempotency.Store, key string) {
+	if err := store.Release(key); err != nil {
+		logrus.WithContext(c.UserContext()).Errorf("release idempotency key error: %v", err)
+	}
+}
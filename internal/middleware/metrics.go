@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"food-delivery-workshop/internal/core/metrics"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Metrics records the duration of each request by method, route pattern and
+// status. Requests that match no route share the "unmatched" route.
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		own := c.Route()
+
+		if err := handleNext(c); err != nil {
+			return err
+		}
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(strings.Clone(c.Method()), routePattern(c, own), strconv.Itoa(c.Response().StatusCode())).
+			Observe(time.Since(start).Seconds())
+		return nil
+	}
+}
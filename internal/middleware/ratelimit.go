@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"fmt"
+	"food-delivery-workshop/internal/core/ratelimit"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimit applies the token bucket policy of group to each caller (user, API
+// key or IP) and answers 429 once the bucket is empty. Every response carries
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy
+// headers. Put it after Authenticate to limit per user instead of per IP.
+func RateLimit(limiter *ratelimit.Limiter, group string) fiber.Handler {
+	policy, ok := limiter.Policy(group)
+	if !ok {
+		panic(fmt.Sprintf("no rate limit policy for group %q", group))
+	}
+	policyHeader := fmt.Sprintf("%d;w=%d", policy.Limit, int(math.Ceil(policy.Period.Seconds())))
+
+	return func(c *fiber.Ctx) error {
+		result, err := limiter.Take(group, callerKey(c))
+		if err != nil {
+			// store ใช้ไม่ได้ก็ไม่ควรทำให้ทุก request ล้มเหลว
+			logrus.WithContext(c.UserContext()).Errorf("rate limit error: %v", err)
+			return c.Next()
+		}
+
+		c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		c.Set("RateLimit-Policy", policyHeader)
+		if !result.Allowed {
+			return ratelimit.Exceeded(result)
+		}
+		return c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"food-delivery-workshop/internal/core/logging"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestID accepts the caller's X-Request-ID or generates one, returns it in
+// the response and puts it in the user context, so logrus.WithContext(ctx)
+// tags every log line of the request with it.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := logging.RequestID(c.Get(fiber.HeaderXRequestID))
+		c.Set(fiber.HeaderXRequestID, requestID)
+		c.Locals("requestid", requestID)
+		c.SetUserContext(logging.NewContext(c.UserContext(), requestID))
+		return c.Next()
+	}
+}
+
+// AccessLog writes one structured line per request. Server errors are logged at
+// error level, client errors at warning level and the rest at info level.
+func AccessLog() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		own := c.Route()
+
+		if err := handleNext(c); err != nil {
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		entry := logrus.WithContext(c.UserContext()).WithFields(logrus.Fields{
+			"method":      c.Method(),
+			"path":        c.Path(),
+			"route":       routePattern(c, own),
+			"status":      status,
+			"duration_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"ip":          c.IP(),
+			"bytes":       len(c.Response().Body()),
+		})
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			entry.Error("request")
+		case status >= fiber.StatusBadRequest:
+			entry.Warn("request")
+		default:
+			entry.Info("request")
+		}
+		return nil
+	}
+}
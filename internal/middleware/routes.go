@@ -1,97 +1,163 @@
 package middleware
 
 import (
+	"food-delivery-workshop/internal/core/metrics"
+	"food-delivery-workshop/internal/core/ratelimit"
+	"food-delivery-workshop/internal/graphapi"
+	"food-delivery-workshop/internal/pkg/apikey"
 	"food-delivery-workshop/internal/pkg/cart"
+	"food-delivery-workshop/internal/pkg/health"
+	"food-delivery-workshop/internal/pkg/order"
 	"food-delivery-workshop/internal/pkg/product"
 	"food-delivery-workshop/internal/pkg/promotion"
+	"food-delivery-workshop/internal/pkg/restaurant"
+	"food-delivery-workshop/internal/pkg/rider"
+	"food-delivery-workshop/internal/pkg/sso"
 	"food-delivery-workshop/internal/pkg/user"
-	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/pkg/webhook"
+	authn "food-delivery-workshop/internal/auth"
 
 	fiberSwagger "github.com/arsmn/fiber-swagger/v2"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/basicauth"
-	jwtware "github.com/gofiber/jwt/v3"
-	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 )
 
-func SetupRoutes(app *fiber.App, userService user.Service, productService product.Service, cartService cart.Service, promotionService promotion.Service) {
-	users := make(map[string]string)
-	users["username"] = "password"
-	basicAuth := basicauth.New(basicauth.Config{
-		Users: users,
-		Unauthorized: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"message": "Unauthorized",
-			})
-		},
-	})
+func SetupRoutes(app *fiber.App, userService user.Service, productService product.Service, cartService cart.Service, promotionService promotion.Service, restaurantService restaurant.Service, orderService order.Service, riderService rider.Service, ssoService sso.Service, apiKeyService apikey.Service, webhookService webhook.Service, idempotent fiber.Handler, rateLimiter *ratelimit.Limiter, healthService health.Service) {
+	// EventSource ในเบราว์เซอร์ตั้ง header ไม่ได้ จึงรับ token จาก query ได้ด้วย
+	streamAuth := Authenticate(apiKeyService, "header:Authorization,query:access_token")
+	auth := Authenticate(apiKeyService, "")
+	admin := RequireScope(authn.ScopeAdmin)
+	authLimit := RateLimit(rateLimiter, ratelimit.GroupAuth)
+	promotionLimit := RateLimit(rateLimiter, ratelimit.GroupPromotion)
 
-	auth := jwtware.New(jwtware.Config{
-		SigningKey: []byte(auth.SecretKey),
-		ErrorHandler: func(c *fiber.Ctx, _ error) error {
-			return basicAuth(c)
-		},
-		SuccessHandler: func(c *fiber.Ctx) error {
-			user := c.Locals("user").(*jwt.Token)
-			claims := user.Claims.(jwt.MapClaims)
-			c.Locals("user_id", claims["user_id"])
-			return c.Next()
-		},
+	// Probes and metrics for Kubernetes and Prometheus
+	app.Get("/healthz", health.Liveness)
+	app.Get("/readyz", func(c *fiber.Ctx) error {
+		return health.Readiness(c, healthService)
 	})
+	app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
 
 	// Routes for Users
-	app.Post("/users/login", func(c *fiber.Ctx) error {
+	app.Post("/users/login", authLimit, func(c *fiber.Ctx) error {
 		return user.Login(c, userService)
 	})
-	app.Post("/users/register", func(c *fiber.Ctx) error {
+	app.Post("/users/register", authLimit, func(c *fiber.Ctx) error {
 		return user.Register(c, userService)
 	})
+	app.Post("/users/otp/request", authLimit, func(c *fiber.Ctx) error {
+		return user.RequestOTP(c, userService)
+	})
+	app.Post("/users/otp/verify", authLimit, func(c *fiber.Ctx) error {
+		return user.VerifyOTP(c, userService)
+	})
+	app.Post("/users/password/forgot", authLimit, func(c *fiber.Ctx) error {
+		return user.ForgotPassword(c, userService)
+	})
+	app.Post("/users/password/reset", authLimit, func(c *fiber.Ctx) error {
+		return user.ResetPassword(c, userService)
+	})
+	app.Post("/users/email/verify", func(c *fiber.Ctx) error {
+		return user.VerifyEmail(c, userService)
+	})
+	app.Get("/auth/:provider/login", func(c *fiber.Ctx) error {
+		return sso.Login(c, ssoService)
+	})
+	app.Get("/auth/:provider/callback", func(c *fiber.Ctx) error {
+		return sso.Callback(c, ssoService)
+	})
 	app.Get("/me", auth, func(c *fiber.Ctx) error {
 		return user.GetUserByID(c, userService)
 	})
+	app.Patch("/me", auth, func(c *fiber.Ctx) error {
+		return user.UpdateProfile(c, userService)
+	})
+	app.Delete("/me", auth, func(c *fiber.Ctx) error {
+		return user.DeleteAccount(c, userService)
+	})
+	app.Post("/me/password", auth, func(c *fiber.Ctx) error {
+		return user.ChangePassword(c, userService)
+	})
+	app.Post("/me/email", auth, func(c *fiber.Ctx) error {
+		return user.RequestEmailChange(c, userService)
+	})
+	app.Post("/me/email/confirm", auth, func(c *fiber.Ctx) error {
+		return user.ConfirmEmailChange(c, userService)
+	})
+	app.Post("/me/email/verification", auth, func(c *fiber.Ctx) error {
+		return user.SendVerification(c, userService)
+	})
+
+	// Routes for admin
+	app.Post("/admin/api-keys", auth, admin, func(c *fiber.Ctx) error {
+		return apikey.Create(c, apiKeyService)
+	})
+	app.Get("/admin/api-keys", auth, admin, func(c *fiber.Ctx) error {
+		return apikey.GetAll(c, apiKeyService)
+	})
+	app.Delete("/admin/api-keys/:id", auth, admin, func(c *fiber.Ctx) error {
+		return apikey.Revoke(c, apiKeyService)
+	})
+	app.Post("/admin/users/:id/unlock", auth, admin, func(c *fiber.Ctx) error {
+		return user.Unlock(c, userService)
+	})
+	app.Post("/admin/webhooks", auth, admin, func(c *fiber.Ctx) error {
+		return webhook.CreateSubscription(c, webhookService)
+	})
+	app.Get("/admin/webhooks", auth, admin, func(c *fiber.Ctx) error {
+		return webhook.GetSubscriptions(c, webhookService)
+	})
+	app.Delete("/admin/webhooks/:id", auth, admin, func(c *fiber.Ctx) error {
+		return webhook.DeleteSubscription(c, webhookService)
+	})
+	app.Get("/admin/webhooks/deliveries", auth, admin, func(c *fiber.Ctx) error {
+		return webhook.GetDeliveries(c, webhookService)
+	})
+	app.Post("/admin/webhooks/deliveries/:id/replay", auth, admin, func(c *fiber.Ctx) error {
+		return webhook.Replay(c, webhookService)
+	})
 
 	// Routes for Products
-	app.Post("/products", auth, func(c *fiber.Ctx) error {
+	app.Post("/products", auth, RequireScope(authn.ScopeProductsWrite), func(c *fiber.Ctx) error {
 		return product.Create(c, productService)
 	})
-	app.Put("/products/:id", auth, func(c *fiber.Ctx) error {
+	app.Put("/products/:id", auth, RequireScope(authn.ScopeProductsWrite), func(c *fiber.Ctx) error {
 		return product.Update(c, productService)
 	})
-	app.Delete("/products/:id", auth, func(c *fiber.Ctx) error {
+	app.Delete("/products/:id", auth, RequireScope(authn.ScopeProductsWrite), func(c *fiber.Ctx) error {
 		return product.Delete(c, productService)
 	})
-	app.Get("/products", auth, func(c *fiber.Ctx) error {
+	app.Get("/products", auth, RequireScope(authn.ScopeProductsRead), func(c *fiber.Ctx) error {
 		return product.GetAllProduct(c, productService)
 	})
-	app.Get("/products/:id", auth, func(c *fiber.Ctx) error {
+	app.Get("/products/:id", auth, RequireScope(authn.ScopeProductsRead), func(c *fiber.Ctx) error {
 		return product.GetProductByID(c, productService)
 	})
 
 	// Routes for Promotions
-	app.Post("/promotions", auth, func(c *fiber.Ctx) error {
+	app.Post("/promotions", auth, RequireScope(authn.ScopePromotionsWrite), func(c *fiber.Ctx) error {
 		return promotion.Create(c, promotionService)
 	})
-	app.Put("/promotions/:id", auth, func(c *fiber.Ctx) error {
+	app.Put("/promotions/:id", auth, RequireScope(authn.ScopePromotionsWrite), func(c *fiber.Ctx) error {
 		return promotion.Update(c, promotionService)
 	})
-	app.Delete("/promotions/:id", auth, func(c *fiber.Ctx) error {
+	app.Delete("/promotions/:id", auth, RequireScope(authn.ScopePromotionsWrite), func(c *fiber.Ctx) error {
 		return promotion.Delete(c, promotionService)
 	})
-	app.Get("/promotions", auth, func(c *fiber.Ctx) error {
+	app.Get("/promotions", auth, RequireScope(authn.ScopePromotionsRead), func(c *fiber.Ctx) error {
 		return promotion.GetAllPromotion(c, promotionService)
 	})
-	app.Get("/promotions/:id", auth, func(c *fiber.Ctx) error {
+	app.Get("/promotions/:id", auth, RequireScope(authn.ScopePromotionsRead), func(c *fiber.Ctx) error {
 		return promotion.GetPromotionByID(c, promotionService)
 	})
 
 	// Routes for Cart
-	app.Post("/cart", auth, func(c *fiber.Ctx) error {
+	app.Post("/cart", auth, idempotent, func(c *fiber.Ctx) error {
 		return cart.Create(c, cartService)
 	})
-	app.Put("/cart", auth, func(c *fiber.Ctx) error {
+	app.Put("/cart", auth, idempotent, func(c *fiber.Ctx) error {
 		return cart.Update(c, cartService)
 	})
-	app.Post("/cart/promotion", auth, func(c *fiber.Ctx) error {
+	app.Post("/cart/promotion", auth, promotionLimit, idempotent, func(c *fiber.Ctx) error {
 		return cart.ApplyPromotion(c, cartService)
 	})
 	app.Delete("/cart/item/:product_id", auth, func(c *fiber.Ctx) error {
@@ -101,6 +167,93 @@ func SetupRoutes(app *fiber.App, userService user.Service, productService produc
 		return cart.GetAllCart(c, cartService)
 	})
 
+	// Routes for Restaurants
+	app.Post("/restaurants", auth, RequireScope(authn.ScopeRestaurantsWrite), func(c *fiber.Ctx) error {
+		return restaurant.Create(c, restaurantService)
+	})
+	app.Put("/restaurants/:id", auth, RequireScope(authn.ScopeRestaurantsWrite), func(c *fiber.Ctx) error {
+		return restaurant.Update(c, restaurantService)
+	})
+	app.Get("/restaurants", auth, RequireScope(authn.ScopeRestaurantsRead), func(c *fiber.Ctx) error {
+		return restaurant.GetAllRestaurant(c, restaurantService)
+	})
+	app.Get("/restaurants/:id", auth, RequireScope(authn.ScopeRestaurantsRead), func(c *fiber.Ctx) error {
+		return restaurant.GetRestaurantByID(c, restaurantService)
+	})
+	app.Get("/restaurants/:id/hours", auth, RequireScope(authn.ScopeRestaurantsRead), func(c *fiber.Ctx) error {
+		return restaurant.GetSchedule(c, restaurantService)
+	})
+	app.Put("/restaurants/:id/hours", auth, RequireScope(authn.ScopeRestaurantsWrite), func(c *fiber.Ctx) error {
+		return restaurant.UpdateHours(c, restaurantService)
+	})
+	app.Get("/restaurants/:id/slots", auth, RequireScope(authn.ScopeRestaurantsRead), func(c *fiber.Ctx) error {
+		return restaurant.GetSlots(c, restaurantService)
+	})
+	app.Post("/restaurants/:id/holidays", auth, RequireScope(authn.ScopeRestaurantsWrite), func(c *fiber.Ctx) error {
+		return restaurant.CreateHoliday(c, restaurantService)
+	})
+	app.Delete("/restaurants/:id/holidays/:holiday_id", auth, RequireScope(authn.ScopeRestaurantsWrite), func(c *fiber.Ctx) error {
+		return restaurant.DeleteHoliday(c, restaurantService)
+	})
+	app.Post("/restaurants/:id/pause", auth, RequireScope(authn.ScopeRestaurantsWrite), func(c *fiber.Ctx) error {
+		return restaurant.Pause(c, restaurantService)
+	})
+	app.Delete("/restaurants/:id/pause", auth, RequireScope(authn.ScopeRestaurantsWrite), func(c *fiber.Ctx) error {
+		return restaurant.Resume(c, restaurantService)
+	})
+
+	// Routes for Orders
+	app.Post("/orders", auth, idempotent, func(c *fiber.Ctx) error {
+		return order.Checkout(c, orderService)
+	})
+	app.Get("/orders", auth, func(c *fiber.Ctx) error {
+		return order.GetAllOrder(c, orderService)
+	})
+	app.Get("/orders/:id", auth, func(c *fiber.Ctx) error {
+		return order.GetOrderByID(c, orderService)
+	})
+	app.Put("/orders/:id/status", auth, RequireScope(authn.ScopeOrdersWrite), func(c *fiber.Ctx) error {
+		return order.UpdateStatus(c, orderService)
+	})
+	app.Get("/orders/:id/stream", streamAuth, func(c *fiber.Ctx) error {
+		return order.Stream(c, orderService)
+	})
+
+	// Routes for Riders
+	app.Post("/rider", auth, func(c *fiber.Ctx) error {
+		return rider.Register(c, riderService)
+	})
+	app.Get("/rider", auth, func(c *fiber.Ctx) error {
+		return rider.GetRider(c, riderService)
+	})
+	app.Put("/rider/status", auth, func(c *fiber.Ctx) error {
+		return rider.UpdateStatus(c, riderService)
+	})
+	app.Post("/rider/location", auth, func(c *fiber.Ctx) error {
+		return rider.UpdateLocation(c, riderService)
+	})
+	app.Get("/rider/offers", auth, func(c *fiber.Ctx) error {
+		return rider.GetOffers(c, riderService)
+	})
+	app.Post("/rider/offers/:id/accept", auth, func(c *fiber.Ctx) error {
+		return rider.AcceptOffer(c, riderService)
+	})
+	app.Post("/rider/offers/:id/decline", auth, func(c *fiber.Ctx) error {
+		return rider.DeclineOffer(c, riderService)
+	})
+	app.Put("/rider/orders/:id/status", auth, func(c *fiber.Ctx) error {
+		return rider.UpdateOrderStatus(c, riderService)
+	})
+
+	// GraphQL for the customer app
+	app.Post("/graphql", auth, graphapi.NewHandler(&graphapi.Resolver{
+		ProductService:   productService,
+		PromotionService: promotionService,
+		CartService:      cartService,
+		UserService:      userService,
+		RateLimiter:      rateLimiter,
+	}))
+
 	// Swagger Route
 	app.Get("/swagger/*", fiberSwagger.HandlerDefault)
 }
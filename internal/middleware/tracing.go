@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"food-delivery-workshop/internal/core/tracing"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ไม่ trace probe และ /metrics ที่ถูกเรียกทุกไม่กี่วินาที
+var untracedPaths = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// Tracing starts a server span per request, continuing the trace of an incoming
+// W3C traceparent header, and puts it in the user context so service and
+// database spans become its children.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if untracedPaths[c.Path()] {
+			return c.Next()
+		}
+
+		own := c.Route()
+		// fiber ใช้ buffer ของ request ซ้ำ ต้อง copy string ที่ span เก็บไว้หลังจบ request
+		method := strings.Clone(c.Method())
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), propagation.HeaderCarrier(c.GetReqHeaders()))
+		ctx, span := tracing.Tracer().Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(strings.Clone(c.Path())),
+				semconv.ClientAddress(c.IP()),
+			))
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := handleNext(c)
+
+		route := routePattern(c, own)
+		status := c.Response().StatusCode()
+		span.SetName(method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+		return err
+	}
+}
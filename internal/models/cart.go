@@ -14,4 +14,5 @@ type Cart struct { // ตะกร้าสินค้า
 	SubTotal    float64    `json:"sub_total" gorm:"-"` // รวม CartItem.Price ของ CartItem
 	Total       float64    `json:"total" gorm:"-"` // รวมทั้งหมด (หลังหักส่วนลด)
 	Discount    float64    `json:"discount" gorm:"-"` //ผลรวมของ Promotion.Discount ของแต่ละ Product
+	ETA         *ETA       `json:"eta" gorm:"-"` // เวลาจัดส่งโดยประมาณ (nil ถ้ายังไม่รู้ร้าน)
 }
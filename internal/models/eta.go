@@ -0,0 +1,12 @@
+package models
+
+import (
+	"time"
+)
+
+type ETA struct { // ช่วงเวลาที่คาดว่าอาหารจะถึงลูกค้า
+	MinMinutes int        `json:"min_minutes"`
+	MaxMinutes int        `json:"max_minutes"`
+	EarliestAt *time.Time `json:"earliest_at"`
+	LatestAt   *time.Time `json:"latest_at"`
+}
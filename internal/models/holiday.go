@@ -0,0 +1,15 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type Holiday struct { // วันหยุดหรือวันที่เปิดเวลาพิเศษ (แทนตารางรายสัปดาห์ของวันนั้น)
+	gorm.Model
+	RestaurantID uint        `json:"restaurant_id" gorm:"index"`
+	Restaurant   *Restaurant `json:"-" gorm:"foreignKey:RestaurantID"`
+	Date         string      `json:"date" gorm:"index"` // YYYY-MM-DD
+	Name         string      `json:"name"`
+	OpensAt      string      `json:"opens_at"` // ว่าง = ปิดทั้งวัน
+	ClosesAt     string      `json:"closes_at"`
+}
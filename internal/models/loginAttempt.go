@@ -0,0 +1,20 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+const (
+	LoginFailureUnknownEmail    = "unknown_email"
+	LoginFailureInvalidPassword = "invalid_password"
+	LoginFailureLocked          = "locked"
+)
+
+type LoginAttempt struct { // บันทึกการ login ที่ไม่สำเร็จ
+	gorm.Model
+	UserID    *uint  `json:"user_id" gorm:"index"`
+	Email     string `json:"email" gorm:"index"`
+	IP        string `json:"ip" gorm:"index"`
+	UserAgent string `json:"user_agent"`
+	Reason    string `json:"reason"`
+}
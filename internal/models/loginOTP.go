@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoginOTP struct { // รหัส OTP สำหรับ login ด้วยเบอร์โทร เก็บเฉพาะ hash
+	gorm.Model
+	UserID     uint       `json:"user_id" gorm:"index"`
+	CodeHash   string     `json:"-"`
+	Attempts   int        `json:"attempts"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ConsumedAt *time.Time `json:"consumed_at"`
+}
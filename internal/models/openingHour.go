@@ -0,0 +1,14 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type OpeningHour struct { // ช่วงเวลาเปิดร้านรายสัปดาห์ (หนึ่งวันมีได้หลายช่วง)
+	gorm.Model
+	RestaurantID uint        `json:"restaurant_id" gorm:"index"`
+	Restaurant   *Restaurant `json:"-" gorm:"foreignKey:RestaurantID"`
+	Weekday      int         `json:"weekday"`   // 0 = อาทิตย์ ... 6 = เสาร์
+	OpensAt      string      `json:"opens_at"`  // HH:MM เวลาไทย
+	ClosesAt     string      `json:"closes_at"` // HH:MM ถ้าน้อยกว่า OpensAt คือปิดหลังเที่ยงคืน
+}
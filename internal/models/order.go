@@ -0,0 +1,40 @@
+package models
+
+import (
+	"food-delivery-workshop/internal/core/pii"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	OrderStatusScheduled = "scheduled" // สั่งล่วงหน้า รอส่งเข้าครัว
+	OrderStatusPlaced    = "placed"
+	OrderStatusPreparing = "preparing"
+	OrderStatusReady     = "ready" // พร้อมให้ไรเดอร์มารับ
+	OrderStatusPickedUp  = "picked_up"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+)
+
+type Order struct { // คำสั่งซื้อที่สร้างจากตะกร้าสินค้า
+	gorm.Model
+	UserID            uint         `json:"user_id"`
+	User              *User        `json:"-" gorm:"foreignKey:UserID"`
+	RestaurantID      uint         `json:"restaurant_id"`
+	Restaurant        *Restaurant  `json:"restaurant" gorm:"foreignKey:RestaurantID"`
+	RiderID           *uint        `json:"rider_id"`
+	Rider             *Rider       `json:"rider" gorm:"foreignKey:RiderID"`
+	PromotionID       *uint        `json:"promotion_id"`
+	Status            string       `json:"status" gorm:"index"`
+	DeliveryAddress   pii.String   `json:"delivery_address" swaggertype:"string"`
+	DeliveryLatitude  float64      `json:"delivery_latitude"`
+	DeliveryLongitude float64      `json:"delivery_longitude"`
+	ScheduledFor      *time.Time   `json:"scheduled_for"`           // เวลาส่งที่ลูกค้าเลือก (nil = ส่งทันที)
+	ReleaseAt         *time.Time   `json:"release_at" gorm:"index"` // เวลาที่ส่งออเดอร์เข้าครัว
+	SubTotal          float64      `json:"sub_total"`
+	Discount          float64      `json:"discount"`
+	Total             float64      `json:"total"`
+	ETA               ETA          `json:"eta" gorm:"embedded;embeddedPrefix:eta_"`
+	OrderItems        []*OrderItem `json:"order_items" gorm:"foreignKey:OrderID"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type OrderItem struct { // รายการสินค้าในคำสั่งซื้อ (เก็บราคา ณ ตอนสั่ง)
+	gorm.Model
+	OrderID    uint     `json:"order_id"`
+	ProductID  uint     `json:"product_id"`
+	Product    *Product `json:"product" gorm:"foreignKey:ProductID"`
+	Quantity   uint     `json:"quantity"`
+	Price      float64  `json:"price"`
+	TotalPrice float64  `json:"total_price"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OutboxEvent struct { // domain event ที่บันทึกใน transaction เดียวกับข้อมูล รอ relay ส่งต่อ
+	gorm.Model
+	EventID       string          `json:"event_id" gorm:"uniqueIndex"`
+	Name          string          `json:"name"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:jsonb"`
+	PublishedAt   *time.Time      `json:"published_at" gorm:"index"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" gorm:"index"`
+	LastError     string          `json:"last_error"`
+}
@@ -6,8 +6,11 @@ import (
 
 type Product struct {
 	gorm.Model
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	Price       float64    `json:"price"`
-	Promotion   *Promotion `json:"-" gorm:"foreignKey:ProductID"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Price        float64     `json:"price"`
+	PrepMinutes  uint        `json:"prep_minutes"` // เวลาเตรียมอาหาร (0 = ใช้ค่าของร้าน)
+	RestaurantID *uint       `json:"restaurant_id"`
+	Restaurant   *Restaurant `json:"-" gorm:"foreignKey:RestaurantID"`
+	Promotion    *Promotion  `json:"-" gorm:"foreignKey:ProductID"`
 }
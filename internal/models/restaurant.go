@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Restaurant struct { // ร้านอาหาร (จุดรับสินค้าของไรเดอร์)
+	gorm.Model
+	Name         string     `json:"name"`
+	Address      string     `json:"address"`
+	Latitude     float64    `json:"latitude"`
+	Longitude    float64    `json:"longitude"`
+	PrepMinutes  uint       `json:"prep_minutes"`  // เวลาเตรียมอาหารโดยเฉลี่ยของร้าน
+	PausedUntil  *time.Time `json:"paused_until"`  // หยุดรับออเดอร์ชั่วคราวถึงเวลานี้
+	SlotCapacity uint       `json:"slot_capacity"` // จำนวนออเดอร์ล่วงหน้าต่อช่วงเวลา (0 = ค่าเริ่มต้น)
+	Products     []*Product `json:"-" gorm:"foreignKey:RestaurantID"`
+}
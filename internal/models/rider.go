@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RiderStatusOffline = "offline"
+	RiderStatusOnline  = "online"
+)
+
+type Rider struct { // ไรเดอร์ (ผูกกับ User)
+	gorm.Model
+	UserID            uint       `json:"user_id" gorm:"uniqueIndex"`
+	User              *User      `json:"-" gorm:"foreignKey:UserID"`
+	VehicleType       string     `json:"vehicle_type"`
+	LicensePlate      string     `json:"license_plate"`
+	Status            string     `json:"status" gorm:"index"`
+	Latitude          float64    `json:"latitude"`
+	Longitude         float64    `json:"longitude"`
+	LocationUpdatedAt *time.Time `json:"location_updated_at"`
+}
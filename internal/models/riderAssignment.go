@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AssignmentStatusOffered  = "offered"
+	AssignmentStatusAccepted = "accepted"
+	AssignmentStatusDeclined = "declined"
+	AssignmentStatusExpired  = "expired"
+)
+
+type RiderAssignment struct { // ประวัติการเสนองานให้ไรเดอร์ (ใช้ตรวจสอบย้อนหลัง)
+	gorm.Model
+	OrderID     uint       `json:"order_id" gorm:"index"`
+	Order       *Order     `json:"order,omitempty" gorm:"foreignKey:OrderID"`
+	RiderID     uint       `json:"rider_id" gorm:"index"`
+	Status      string     `json:"status" gorm:"index"`
+	Distance    float64    `json:"distance"` // ระยะทางจากไรเดอร์ถึงร้าน (กม.)
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at"`
+}
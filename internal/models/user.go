@@ -1,19 +1,45 @@
 package models
 
 import (
+	"food-delivery-workshop/internal/core/pii"
+	"time"
+
 	"gorm.io/gorm"
 )
 
+const (
+	RoleCustomer = "customer"
+	RoleStaff    = "staff"
+	RoleAdmin    = "admin"
+)
+
 type User struct {
 	gorm.Model
-	FirstName      string `json:"first_name" validate:"required"`
-	LastName       string `json:"last_name" validate:"required"`
-	Email          string `json:"email" validate:"required"`
-	Password       string `json:"password" validate:"required"`
-	Phone          string `json:"phone" `
-	IDCard         string `json:"id_card"`
-	Address        string `json:"address"`
-	AddressDetails string `json:"address_details"`
-	Cart           []Cart `json:"-" gorm:"foreignKey:UserID"`
+	FirstName       string     `json:"first_name" validate:"required"`
+	LastName        string     `json:"last_name" validate:"required"`
+	Email           string     `json:"email" validate:"required"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Password        string     `json:"password" validate:"required"`
+	Phone           pii.String `json:"phone" swaggertype:"string"`
+	PhoneIndex      string     `json:"-" gorm:"index"` // blind index ใช้ค้นหาโดยไม่ต้องถอดรหัส ไม่ unique เพราะข้อมูลเก่ามีเบอร์ซ้ำ (ดู FindByPhone)
+	IDCard          pii.String `json:"id_card" swaggertype:"string"`
+	IDCardIndex     string     `json:"-" gorm:"index"`
+	Address         pii.String `json:"address" swaggertype:"string"`
+	AddressDetails  pii.String `json:"address_details" swaggertype:"string"`
+	Roles           []string   `json:"roles" gorm:"serializer:json"`
+	Cart            []Cart     `json:"-" gorm:"foreignKey:UserID"`
 }
 
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if len(u.Roles) == 0 {
+		u.Roles = []string{RoleCustomer}
+	}
+	return nil
+}
+
+// BeforeSave keeps the blind indexes in step with the encrypted columns.
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	u.PhoneIndex = pii.BlindIndex(u.Phone.Plain())
+	u.IDCardIndex = pii.BlindIndex(u.IDCard.Plain())
+	return nil
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type UserIdentity struct { // บัญชี social login ที่ผูกกับผู้ใช้
+	gorm.Model
+	UserID   uint   `json:"user_id" gorm:"index"`
+	Provider string `json:"provider" gorm:"uniqueIndex:idx_user_identity_subject"`
+	Subject  string `json:"-" gorm:"uniqueIndex:idx_user_identity_subject"`
+	Email    string `json:"email"`
+}
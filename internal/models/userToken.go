@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	UserTokenEmailChange       = "email_change"
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+)
+
+type UserToken struct { // token ใช้ครั้งเดียว เก็บเฉพาะ hash
+	gorm.Model
+	UserID    uint       `json:"user_id" gorm:"index"`
+	Purpose   string     `json:"purpose"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex"`
+	Email     string     `json:"email"` // อีเมลใหม่ที่รอยืนยัน
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
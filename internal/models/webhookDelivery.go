@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead" // ส่งไม่สำเร็จครบจำนวนครั้งแล้ว รอ replay
+)
+
+type WebhookDelivery struct { // คิวการส่ง event หนึ่งรายการไปยัง subscription หนึ่ง
+	gorm.Model
+	SubscriptionID uint                 `json:"subscription_id" gorm:"index"`
+	Subscription   *WebhookSubscription `json:"-" gorm:"foreignKey:SubscriptionID"`
+	EventID        string               `json:"event_id" gorm:"index"` // เหมือนกันทุกครั้งที่ส่งซ้ำ ให้ปลายทางใช้กันซ้ำ
+	EventType      string               `json:"event_type"`
+	Payload        json.RawMessage      `json:"payload" gorm:"type:jsonb" swaggertype:"object"`
+	Status         string               `json:"status" gorm:"index"`
+	Attempts       int                  `json:"attempts"`
+	NextAttemptAt  time.Time            `json:"next_attempt_at" gorm:"index"`
+	LastAttemptAt  *time.Time           `json:"last_attempt_at"`
+	ResponseStatus int                  `json:"response_status"`
+	LastError      string               `json:"last_error"`
+	DeliveredAt    *time.Time           `json:"delivered_at"`
+	ReplayOfID     *uint                `json:"replay_of_id"`
+}
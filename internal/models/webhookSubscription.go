@@ -0,0 +1,14 @@
+package models
+
+import (
+	"food-delivery-workshop/internal/core/pii"
+
+	"gorm.io/gorm"
+)
+
+type WebhookSubscription struct { // ปลายทางที่ partner ลงทะเบียนไว้รับ event
+	gorm.Model
+	URL        string     `json:"url"`
+	Secret     pii.String `json:"-"` // ต้องถอดรหัสได้เพื่อใช้เซ็น HMAC จึงเข้ารหัสแทนการเก็บ hash
+	EventTypes []string   `json:"event_types" gorm:"serializer:json"`
+}
@@ -0,0 +1,91 @@
+package apikey
+
+import (
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/validation"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Create Create API key
+// @Summary Create an API key
+// @Description Create a key for a server-to-server client. The key is only returned once; store it securely. (admin)
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param request body CreateRequest true "API key"
+// @Success 201 {object} CreateResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /admin/api-keys [post]
+func Create(c *fiber.Ctx, service Service) error {
+	request, err := validation.BindAndValidate[CreateRequest](c)
+	if err != nil {
+		return err
+	}
+
+	response, err := service.Create(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// GetAll List API keys
+// @Summary List API keys
+// @Description List all API keys, including revoked ones (admin)
+// @Tags admin
+// @Produce  json
+// @Success 200 {array} models.APIKey
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /admin/api-keys [get]
+func GetAll(c *fiber.Ctx, service Service) error {
+	apiKeys, err := service.GetAll(c.UserContext())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiKeys)
+}
+
+// Revoke Revoke API key
+// @Summary Revoke an API key
+// @Description Revoke an API key; requests using it are rejected right away (admin)
+// @Tags admin
+// @Produce  json
+// @Param id path int true "API key ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /admin/api-keys/{id} [delete]
+func Revoke(c *fiber.Ctx, service Service) error {
+	apiKeyID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid API key ID")
+	}
+
+	if err := service.Revoke(c.UserContext(), &get.GetOne[uint]{ID: uint(apiKeyID)}); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "API key revoked",
+	})
+}
@@ -0,0 +1,38 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	keyPrefix     = "fdk_"
+	displayLength = 12
+)
+
+// newKey returns a random key for the client and the hash that is stored.
+func newKey() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key := keyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, hashKey(key), nil
+}
+
+// keys are long random strings, so a plain sha256 is enough; no salt or
+// slow hash is needed as for passwords.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// displayPrefix is kept in clear so admins can tell keys apart.
+func displayPrefix(key string) string {
+	if len(key) < displayLength {
+		return key
+	}
+	return key[:displayLength]
+}
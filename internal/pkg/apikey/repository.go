@@ -0,0 +1,55 @@
+package apikey
+
+import (
+	"context"
+	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(ctx context.Context, apiKey *models.APIKey) error
+	FindByHash(ctx context.Context, keyHash string, apiKey *models.APIKey) error
+	FindByID(ctx context.Context, id uint, apiKey *models.APIKey) error
+	FindAll(ctx context.Context) ([]*models.APIKey, error)
+	Revoke(ctx context.Context, id uint, at time.Time) error
+	Touch(ctx context.Context, id uint, at time.Time) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, apiKey *models.APIKey) error {
+	return database.Conn(ctx, r.db).Create(apiKey).Error
+}
+
+func (r *repository) FindByHash(ctx context.Context, keyHash string, apiKey *models.APIKey) error {
+	return database.Conn(ctx, r.db).Where("key_hash = ?", keyHash).First(apiKey).Error
+}
+
+func (r *repository) FindByID(ctx context.Context, id uint, apiKey *models.APIKey) error {
+	return database.Conn(ctx, r.db).First(apiKey, id).Error
+}
+
+func (r *repository) FindAll(ctx context.Context) ([]*models.APIKey, error) {
+	var apiKeys []*models.APIKey
+	if err := database.Conn(ctx, r.db).Order("id DESC").Find(&apiKeys).Error; err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (r *repository) Revoke(ctx context.Context, id uint, at time.Time) error {
+	return database.Conn(ctx, r.db).Model(&models.APIKey{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", at).Error
+}
+
+func (r *repository) Touch(ctx context.Context, id uint, at time.Time) error {
+	return database.Conn(ctx, r.db).Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
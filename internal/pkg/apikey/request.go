@@ -0,0 +1,17 @@
+package apikey
+
+import (
+	"food-delivery-workshop/internal/models"
+	"time"
+)
+
+type CreateRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,unique,dive,api_scope"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type CreateResponse struct {
+	APIKey *models.APIKey `json:"api_key"`
+	Key    string         `json:"key"` // แสดงครั้งเดียวตอนสร้าง
+}
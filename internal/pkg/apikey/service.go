@@ -0,0 +1,149 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/core/tracing"
+	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	Create(ctx context.Context, request *CreateRequest) (*CreateResponse, error)
+	GetAll(ctx context.Context) ([]*models.APIKey, error)
+	Revoke(ctx context.Context, request *get.GetOne[uint]) error
+	Authenticate(ctx context.Context, key string) (*models.APIKey, error)
+	Bootstrap(ctx context.Context, key string) error
+}
+
+const (
+	touchInterval      = time.Minute
+	minBootstrapLength = 32
+)
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Create(ctx context.Context, request *CreateRequest) (*CreateResponse, error) {
+	ctx, span := tracing.Start(ctx, "apikey.Create")
+	defer span.End()
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return nil, apperror.Validation("INVALID_EXPIRY", "expires_at must be in the future")
+	}
+
+	key, keyHash, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	apiKey := &models.APIKey{
+		Name:      request.Name,
+		Prefix:    displayPrefix(key),
+		KeyHash:   keyHash,
+		Scopes:    request.Scopes,
+		ExpiresAt: request.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, apiKey); err != nil {
+		logrus.WithContext(ctx).Errorf("create api key error: %v", err)
+		return nil, err
+	}
+	return &CreateResponse{APIKey: apiKey, Key: key}, nil
+}
+
+func (s *service) GetAll(ctx context.Context) ([]*models.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "apikey.GetAll")
+	defer span.End()
+
+	apiKeys, err := s.repo.FindAll(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find api keys error: %v", err)
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (s *service) Revoke(ctx context.Context, request *get.GetOne[uint]) error {
+	ctx, span := tracing.Start(ctx, "apikey.Revoke")
+	defer span.End()
+
+	apiKey := &models.APIKey{}
+	if err := s.repo.FindByID(ctx, request.ID, apiKey); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("API_KEY_NOT_FOUND", "API key not found")
+		}
+		return err
+	}
+	// กันไม่ให้ revoke key ที่ใช้เรียกอยู่เอง แล้วไม่เหลือ admin key
+	if principal, ok := auth.FromContext(ctx); ok && principal.APIKeyID == apiKey.ID {
+		return apperror.Conflict("CANNOT_REVOKE_OWN_KEY", "an API key cannot revoke itself")
+	}
+	return s.repo.Revoke(ctx, apiKey.ID, time.Now())
+}
+
+// Authenticate returns the active key matching the raw key sent by a client.
+func (s *service) Authenticate(ctx context.Context, key string) (*models.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "apikey.Authenticate")
+	defer span.End()
+
+	apiKey := &models.APIKey{}
+	if err := s.repo.FindByHash(ctx, hashKey(key), apiKey); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errInvalidKey()
+		}
+		logrus.WithContext(ctx).Errorf("find api key error: %v", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt)) {
+		return nil, errInvalidKey()
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > touchInterval {
+		if err := s.repo.Touch(ctx, apiKey.ID, now); err != nil {
+			logrus.WithContext(ctx).Errorf("touch api key error: %v", err)
+		}
+	}
+	return apiKey, nil
+}
+
+// Bootstrap registers key (from ADMIN_API_KEY) as an admin key, so the first
+// keys can be created without any other admin credential.
+func (s *service) Bootstrap(ctx context.Context, key string) error {
+	ctx, span := tracing.Start(ctx, "apikey.Bootstrap")
+	defer span.End()
+
+	if len(key) < minBootstrapLength {
+		return errors.New("bootstrap API key must be at least 32 characters")
+	}
+
+	existing := &models.APIKey{}
+	err := s.repo.FindByHash(ctx, hashKey(key), existing)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return s.repo.Create(ctx, &models.APIKey{
+		Name:    "bootstrap",
+		Prefix:  displayPrefix(key),
+		KeyHash: hashKey(key),
+		Scopes:  []string{auth.ScopeAdmin},
+	})
+}
+
+func errInvalidKey() error {
+	return apperror.Unauthorized("INVALID_API_KEY", "API key is invalid, expired or revoked")
+}
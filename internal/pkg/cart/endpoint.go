@@ -1,6 +1,9 @@
 package cart
 
 import (
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/validation"
 	"strconv"
 	"github.com/gofiber/fiber/v2"
 )
@@ -12,27 +15,30 @@ import (
 // @Accept  json
 // @Produce  json
 // @Param request body CreateRequest true "Cart  request"
+// @Param Idempotency-Key header string false "Retries with the same key replay the first response"
 // @Success 201 {object} models.Cart
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
 // @Router /cart [post]
 func Create(c *fiber.Ctx, service Service) error {
-	userID := c.Locals("user_id").(float64)
-	request := new(CreateRequest)
-	if err := c.BodyParser(request); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := validation.BindAndValidate[CreateRequest](c)
+	if err != nil {
+		return err
 	}
 
-	request.UserID = uint(userID)
-	cartItem, err := service.Create(c, request)
+	request.UserID = principal.UserID
+	cartItem, err := service.Create(c.UserContext(), request)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(cartItem)
@@ -40,38 +46,35 @@ func Create(c *fiber.Ctx, service Service) error {
 
 // Update updates cart
 // @Summary Update a Cart
-// @Description Update a Cart
+// @Description Replace the cart items. Items with quantity 0 are removed from the cart.
 // @Tags cart
 // @Accept  json
 // @Produce  json
 // @Param request body UpdateRequest true "Cart Request"
+// @Param Idempotency-Key header string false "Retries with the same key replay the first response"
 // @Success 200 {object} models.Cart
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
 // @Router /cart [Put]
 func Update(c *fiber.Ctx, service Service) error {
-	userID := c.Locals("user_id").(float64)
-	request := new(UpdateRequest)
-	if err := c.BodyParser(request); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
 	}
 
-	request.UserID = uint(userID)
-	updateCart, err := service.Update(c, request)
+	request, err := validation.BindAndValidate[UpdateRequest](c)
 	if err != nil {
-		if err.Error() == "cart not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
+	}
+
+	request.UserID = principal.UserID
+	updateCart, err := service.Update(c.UserContext(), request)
+	if err != nil {
+		return err
 	}
 	return c.Status(fiber.StatusOK).JSON(updateCart)
 }
@@ -83,33 +86,31 @@ func Update(c *fiber.Ctx, service Service) error {
 // @Accept json
 // @Produce json
 // @Param request body PromotionRequest true "Promotion code request"
+// @Param Idempotency-Key header string false "Retries with the same key replay the first response"
 // @Success 200 {object} models.Cart
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
+// @Failure 429 {object} middleware.ErrorResponse
 // @Router /cart/promotion [post]
 func ApplyPromotion(c *fiber.Ctx, service Service) error {
-	userID := c.Locals("user_id").(float64)
-	request := new(PromotionRequest)
-	if err := c.BodyParser(request); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
 	}
 
-	request.UserID = uint(userID)
-	cart, err := service.ApplyPromotion(c, request)
+	request, err := validation.BindAndValidate[PromotionRequest](c)
 	if err != nil {
-		if err.Error() == "cart not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
+	}
+
+	request.UserID = principal.UserID
+	cart, err := service.ApplyPromotion(c.UserContext(), request)
+	if err != nil {
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(cart)
@@ -123,25 +124,26 @@ func ApplyPromotion(c *fiber.Ctx, service Service) error {
 // @Produce json
 // @Param product_id path int true "Product ID to remove"
 // @Success 200 {object} models.Cart "Updated cart details"
-// @Failure 400 {object} map[string]string 
-// @Failure 401 {object} map[string]string 
-// @Failure 500 {object} map[string]string 
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
 // @Router /cart/item/{product_id} [delete]
 // @Security ApiKeyAuth
 func RemoveCartItem(c *fiber.Ctx, service Service) error {
-	userID := c.Locals("user_id").(float64)
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
 	productID, err := strconv.Atoi(c.Params("product_id"))
 	if err != nil || productID <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid product_id",
-		})
+		return apperror.Validation("INVALID_ID", "invalid product_id")
 	}
 
-	updatedCart, err := service.RemoveItem(c, &RemoveItemRequest{UserID: uint(userID), ProductID: uint(productID)})
+	updatedCart, err := service.RemoveItem(c.UserContext(), &RemoveItemRequest{UserID: principal.UserID, ProductID: uint(productID)})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(updatedCart)
@@ -153,19 +155,30 @@ func RemoveCartItem(c *fiber.Ctx, service Service) error {
 // @Tags cart
 // @Accept json
 // @Produce json
+// @Param latitude query number false "Delivery latitude used for the ETA"
+// @Param longitude query number false "Delivery longitude used for the ETA"
 // @Success 200 {object} models.Cart "Cart"
-// @Failure 400 {object} map[string]string 
-// @Failure 401 {object} map[string]string 
-// @Failure 500 {object} map[string]string 
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
 // @Router /cart [get]
 // @Security ApiKeyAuth
 func GetAllCart(c *fiber.Ctx, service Service) error {
-    userID := c.Locals("user_id").(float64)
-    cart, err := service.GetAllCart(c, &GetAllRequests{UserID: uint(userID)})
+    principal, err := auth.CurrentUser(c)
+    if err != nil {
+    	return err
+    }
+
+    request, err := validation.BindAndValidate[GetAllRequests](c)
+    if err != nil {
+        return err
+    }
+
+    request.UserID = principal.UserID
+    cart, err := service.GetAllCart(c.UserContext(), request)
     if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "error": err.Error(),
-        })
+        return err
     }
 
     return c.Status(fiber.StatusOK).JSON(cart)
@@ -1,6 +1,8 @@
 package cart
 
 import (
+	"context"
+	"food-delivery-workshop/internal/core/database"
 	"food-delivery-workshop/internal/models"
 	"gorm.io/gorm"
 	"github.com/sirupsen/logrus"
@@ -8,19 +10,19 @@ import (
 )
 
 type Repository interface {
-	CreateCartItem(cartItem *models.CartItem) error
-	FindCartByUserID(userID uint) (*models.Cart, error)
-	CreateCart(cart *models.Cart) error
-	UpdateCart(cart *models.Cart) error
-	UpdateCartItem(cart *models.CartItem) error
-	FindCartItem(cartID uint, productID uint) (*models.CartItem, error)
-	DeleteCartItem(cartID uint, productID uint) error
-	RemoveItem(cartID uint, cartItemID uint) error
-	DeleteCart(cartID uint) error 
-	Preload(cart interface{}) error
-	DeleteAllCartItems(cartID uint) error
-	CountCartItems(cartID uint) (int64, error)
-	FindCartItemsByCartID(cartID uint) ([]*models.CartItem, error)
+	CreateCartItem(ctx context.Context, cartItem *models.CartItem) error
+	FindCartByUserID(ctx context.Context, userID uint) (*models.Cart, error)
+	CreateCart(ctx context.Context, cart *models.Cart) error
+	UpdateCart(ctx context.Context, cart *models.Cart) error
+	UpdateCartItem(ctx context.Context, cart *models.CartItem) error
+	FindCartItem(ctx context.Context, cartID uint, productID uint) (*models.CartItem, error)
+	DeleteCartItem(ctx context.Context, cartID uint, productID uint) error
+	RemoveItem(ctx context.Context, cartID uint, cartItemID uint) error
+	DeleteCart(ctx context.Context, cartID uint) error 
+	Preload(ctx context.Context, cart interface{}) error
+	DeleteAllCartItems(ctx context.Context, cartID uint) error
+	CountCartItems(ctx context.Context, cartID uint) (int64, error)
+	FindCartItemsByCartID(ctx context.Context, cartID uint) ([]*models.CartItem, error)
 }
 
 type repository struct {
@@ -31,19 +33,19 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Preload(cart interface{}) error {
-	return r.db.Preload("CartItems.Product").Find(cart).Error
+func (r *repository) Preload(ctx context.Context, cart interface{}) error {
+	return database.Conn(ctx, r.db).Preload("CartItems.Product").Find(cart).Error
 }
-func (r *repository) CreateCartItem(cartItem *models.CartItem) error {
-	if err := r.db.Create(cartItem).Error; err != nil {
+func (r *repository) CreateCartItem(ctx context.Context, cartItem *models.CartItem) error {
+	if err := database.Conn(ctx, r.db).Create(cartItem).Error; err != nil {
 		return err
 	}
-	return r.db.Preload("Product").First(cartItem, cartItem.ID).Error
+	return database.Conn(ctx, r.db).Preload("Product").First(cartItem, cartItem.ID).Error
 }
 
-func (r *repository) FindCartByUserID(userID uint) (*models.Cart, error) {
+func (r *repository) FindCartByUserID(ctx context.Context, userID uint) (*models.Cart, error) {
 	cart := &models.Cart{}
-	err := r.db.Preload("CartItems.Product").
+	err := database.Conn(ctx, r.db).Preload("CartItems.Product").
 	Preload("Promotion").
 	Where("user_id = ?", userID).First(cart).Error
 	if err != nil {
@@ -53,36 +55,36 @@ func (r *repository) FindCartByUserID(userID uint) (*models.Cart, error) {
 	return cart, nil
 }
 
-func (r *repository) CreateCart(cart *models.Cart) error {
-	err := r.db.Create(cart).Error
+func (r *repository) CreateCart(ctx context.Context, cart *models.Cart) error {
+	err := database.Conn(ctx, r.db).Create(cart).Error
 	if err != nil {
 		return err
 	}
 
-	return r.db.Preload("CartItems.Product").First(cart, cart.ID).Error
+	return database.Conn(ctx, r.db).Preload("CartItems.Product").First(cart, cart.ID).Error
 }
 
-func (r *repository) UpdateCart(cart *models.Cart) error {
-	err := r.db.Save(cart).Error
+func (r *repository) UpdateCart(ctx context.Context, cart *models.Cart) error {
+	err := database.Conn(ctx, r.db).Save(cart).Error
 	if err != nil {
-		logrus.Errorf("failed to update cart: %v", err)
+		logrus.WithContext(ctx).Errorf("failed to update cart: %v", err)
 		return err
 	}
 	return nil
 }
 
-func (r *repository) UpdateCartItem(cart *models.CartItem) error {
-	err := r.db.Save(cart).Error
+func (r *repository) UpdateCartItem(ctx context.Context, cart *models.CartItem) error {
+	err := database.Conn(ctx, r.db).Save(cart).Error
     if err!= nil {
-        logrus.Errorf("failed to update cart item: %v", err)
+        logrus.WithContext(ctx).Errorf("failed to update cart item: %v", err)
         return err
     }
     return nil
 }
 
-func (r *repository) FindCartItem(cartID uint, productID uint) (*models.CartItem, error) {
+func (r *repository) FindCartItem(ctx context.Context, cartID uint, productID uint) (*models.CartItem, error) {
 	cartItem := &models.CartItem{}
-	err := r.db.Where("cart_id = ? AND product_id =?", cartID, productID).First(cartItem).Error
+	err := database.Conn(ctx, r.db).Where("cart_id = ? AND product_id =?", cartID, productID).First(cartItem).Error
 	if err != nil {
 		return nil, err
 	}
@@ -90,47 +92,47 @@ func (r *repository) FindCartItem(cartID uint, productID uint) (*models.CartItem
 	return cartItem, nil
 }
 
-func (r *repository) DeleteCartItem(cartID uint, productID uint) error {
-	if err := r.db.Where("cart_id = ? AND product_id = ?", cartID, productID).Delete(&models.CartItem{}).Error; err != nil {
+func (r *repository) DeleteCartItem(ctx context.Context, cartID uint, productID uint) error {
+	if err := database.Conn(ctx, r.db).Where("cart_id = ? AND product_id = ?", cartID, productID).Delete(&models.CartItem{}).Error; err != nil {
 		return err
 	}
     return nil
 }
 
-func (r *repository) RemoveItem(cartID uint, cartItemID uint) error {
-	if err := r.db.Where("card_id = ? AND id = ?", cartID, cartItemID).Delete(&models.CartItem{}).Error; err != nil {
-		logrus.Errorf("failed to delete cart item: %v", err)
+func (r *repository) RemoveItem(ctx context.Context, cartID uint, cartItemID uint) error {
+	if err := database.Conn(ctx, r.db).Where("card_id = ? AND id = ?", cartID, cartItemID).Delete(&models.CartItem{}).Error; err != nil {
+		logrus.WithContext(ctx).Errorf("failed to delete cart item: %v", err)
 		return err
 	}
 	return nil
 }
 
-func (r *repository) DeleteCart(cartID uint) error {
-	if err := r.db.Where("id =?", cartID).Delete(&models.Cart{}).Error; err != nil {
+func (r *repository) DeleteCart(ctx context.Context, cartID uint) error {
+	if err := database.Conn(ctx, r.db).Where("id =?", cartID).Delete(&models.Cart{}).Error; err != nil {
         return err
     }
     return nil
 }
 
-func (r *repository) DeleteAllCartItems(cartID uint) error {
-	if err := r.db.Where("cart_id =?", cartID).Delete(&models.CartItem{}).Error; err != nil {
+func (r *repository) DeleteAllCartItems(ctx context.Context, cartID uint) error {
+	if err := database.Conn(ctx, r.db).Where("cart_id =?", cartID).Delete(&models.CartItem{}).Error; err != nil {
         return err
     }
     return nil
 }
 
-func (r *repository) CountCartItems(cartID uint) (int64, error) {
+func (r *repository) CountCartItems(ctx context.Context, cartID uint) (int64, error) {
 	var count int64
-    err := r.db.Model(&models.CartItem{}).Where("cart_id =?", cartID).Count(&count).Error
+    err := database.Conn(ctx, r.db).Model(&models.CartItem{}).Where("cart_id =?", cartID).Count(&count).Error
     if err != nil {
         return 0, err
     }
     return count, nil
 }
 
-func (r *repository) FindCartItemsByCartID(cartID uint) ([]*models.CartItem, error) {
+func (r *repository) FindCartItemsByCartID(ctx context.Context, cartID uint) ([]*models.CartItem, error) {
 	var cartItems []*models.CartItem
-	err := r.db.Preload("Product").
+	err := database.Conn(ctx, r.db).Preload("Product").
 	Where("cart_id =?", cartID).Find(&cartItems).Error
 	if err != nil {
 		return nil, err
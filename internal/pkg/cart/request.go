@@ -1,18 +1,22 @@
 package cart
 
+import "time"
+
 type CartItemRequest struct {
 	ProductID uint `json:"product_id" validate:"required"`
-	Quantity  uint `json:"quantity" validate:"required,min=1"`
+	Quantity  uint `json:"quantity" validate:"min=0"` // 0 ใน PUT /cart คือเอาสินค้าออก ส่วน POST /cart จะตอบ INVALID_QUANTITY
 }
 
 type CreateRequest struct {
 	UserID           uint              `json:"-"`
-	CartItemRequests []CartItemRequest `json:"cart_items"`
+	CartItemRequests []CartItemRequest `json:"cart_items" validate:"required,min=1,dive"`
+	ScheduledFor     *time.Time        `json:"scheduled_for"` // สั่งล่วงหน้า: ตรวจช่วงเวลาส่งแทนเวลาเปิดร้านตอนนี้
 }
 
 type UpdateRequest struct {
 	UserID           uint              `json:"-"`
-	CartItemRequests []CartItemRequest `json:"cart_items"`
+	CartItemRequests []CartItemRequest `json:"cart_items" validate:"required,min=1,dive"`
+	ScheduledFor     *time.Time        `json:"scheduled_for"` // เหมือน CreateRequest
 }
 
 type PromotionRequest struct {
@@ -26,5 +30,7 @@ type RemoveItemRequest struct {
 }
 
 type GetAllRequests struct {
-	UserID uint `json:"-"`
+	UserID    uint     `json:"-"`
+	Latitude  *float64 `query:"latitude" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude *float64 `query:"longitude" validate:"required_with=Latitude,omitempty,longitude"`
 }
\ No newline at end of file
@@ -1,37 +1,53 @@
 package cart
 
 import (
+	"context"
 	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/events"
+	"food-delivery-workshop/internal/core/metrics"
+	"food-delivery-workshop/internal/core/tracing"
 	"food-delivery-workshop/internal/models"
+	"food-delivery-workshop/internal/pkg/eta"
 	product "food-delivery-workshop/internal/pkg/product"
 	promotion "food-delivery-workshop/internal/pkg/promotion"
-	"github.com/gofiber/fiber/v2"
+	"food-delivery-workshop/internal/pkg/restaurant"
+	"time"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type Service interface {
-	Create(c *fiber.Ctx, request *CreateRequest) (*models.Cart, error)
-	ApplyPromotion(c *fiber.Ctx, request *PromotionRequest) (*models.Cart, error)
-	Update(c *fiber.Ctx, request *UpdateRequest) (*models.Cart, error)
-	RemoveItem(c *fiber.Ctx, request *RemoveItemRequest) (*models.Cart, error)
-	GetAllCart(c *fiber.Ctx, request *GetAllRequests) (*models.Cart, error)
+	Create(ctx context.Context, request *CreateRequest) (*models.Cart, error)
+	ApplyPromotion(ctx context.Context, request *PromotionRequest) (*models.Cart, error)
+	Update(ctx context.Context, request *UpdateRequest) (*models.Cart, error)
+	RemoveItem(ctx context.Context, request *RemoveItemRequest) (*models.Cart, error)
+	GetAllCart(ctx context.Context, request *GetAllRequests) (*models.Cart, error)
 }
 
 type service struct {
-	repo        Repository
-	promoRepo   promotion.Repository
-	productRepo product.Repository
+	repo              Repository
+	promoRepo         promotion.Repository
+	productRepo       product.Repository
+	restaurantService restaurant.Service
+	etaService        eta.Service
+	events            events.Recorder
 }
 
-func NewService(repo Repository, promoRepo promotion.Repository, productRepo product.Repository) Service {
-	return &service{repo: repo, promoRepo: promoRepo, productRepo: productRepo}
+func NewService(repo Repository, promoRepo promotion.Repository, productRepo product.Repository, restaurantService restaurant.Service, etaService eta.Service, recorder events.Recorder) Service {
+	return &service{repo: repo, promoRepo: promoRepo, productRepo: productRepo, restaurantService: restaurantService, etaService: etaService, events: recorder}
 }
 
-func (s *service) CalculateCartItem(cartItem *models.CartItem) error {
-	product, err := s.productRepo.FindByProductID(cartItem.ProductID)
+func (s *service) CalculateCartItem(ctx context.Context, cartItem *models.CartItem) error {
+	ctx, span := tracing.Start(ctx, "cart.CalculateCartItem")
+	defer span.End()
+
+	product, err := s.productRepo.FindByProductID(ctx, cartItem.ProductID)
 	if err != nil {
-		logrus.Errorf("find product error: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("PRODUCT_NOT_FOUND", "product not found")
+		}
+		logrus.WithContext(ctx).Errorf("find product error: %v", err)
 		return err
 	}
 
@@ -41,11 +57,14 @@ func (s *service) CalculateCartItem(cartItem *models.CartItem) error {
 	return nil
 }
 
-func (s *service) CalculateCart(cart *models.Cart) error {
+func (s *service) CalculateCart(ctx context.Context, cart *models.Cart) error {
+	ctx, span := tracing.Start(ctx, "cart.CalculateCart")
+	defer span.End()
+
 	var totalAmount float64
 	for _, cartItem := range cart.CartItems {
-		if err := s.CalculateCartItem(cartItem); err != nil {
-			logrus.Errorf("calculate cart item error: %v", err)
+		if err := s.CalculateCartItem(ctx, cartItem); err != nil {
+			logrus.WithContext(ctx).Errorf("calculate cart item error: %v", err)
 			return err
 		}
 		totalAmount += cartItem.TotalPrice
@@ -56,149 +75,242 @@ func (s *service) CalculateCart(cart *models.Cart) error {
 
 	return nil
 }
-func (s *service) Create(c *fiber.Ctx, request *CreateRequest) (*models.Cart, error) {
-	existingCart, err := s.repo.FindCartByUserID(request.UserID)
+
+func (s *service) EstimateCart(ctx context.Context, cart *models.Cart, latitude, longitude *float64) error {
+	ctx, span := tracing.Start(ctx, "cart.EstimateCart")
+	defer span.End()
+
+	estimate, err := s.etaService.EstimateCart(ctx, cart, latitude, longitude)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("estimate cart error: %v", err)
+		return err
+	}
+
+	cart.ETA = estimate
+	return nil
+}
+
+// checkRestaurantOpen rejects items from a restaurant that is closed right
+// now, or that cannot deliver at scheduledFor when pre-ordering. Every item is
+// checked, each restaurant once.
+func (s *service) checkRestaurantOpen(ctx context.Context, requests []CartItemRequest, scheduledFor *time.Time) error {
+	checked := map[uint]bool{}
+	for _, req := range requests {
+		product, err := s.productRepo.FindByProductID(ctx, req.ProductID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperror.NotFound("PRODUCT_NOT_FOUND", "product not found")
+			}
+			logrus.WithContext(ctx).Errorf("find product error: %v", err)
+			return err
+		}
+		if product.RestaurantID == nil || checked[*product.RestaurantID] {
+			continue
+		}
+		checked[*product.RestaurantID] = true
+
+		if scheduledFor != nil {
+			err = s.restaurantService.CheckSlot(ctx, *product.RestaurantID, *scheduledFor)
+		} else {
+			err = s.restaurantService.CheckOpen(ctx, *product.RestaurantID, time.Now())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) Create(ctx context.Context, request *CreateRequest) (*models.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.Create")
+	defer span.End()
+
+	existingCart, err := s.repo.FindCartByUserID(ctx, request.UserID)
 	if existingCart != nil {
-		return nil, errors.New("cart already exists")
+		return nil, apperror.Conflict("CART_EXISTS", "cart already exists")
 	}
 
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		logrus.Errorf("find cart error: %v", err)
+		logrus.WithContext(ctx).Errorf("find cart error: %v", err)
 		return nil, err
 	}
 
 	if len(request.CartItemRequests) == 0 {
-		return nil, errors.New("cart_items cannot be empty")
+		return nil, apperror.Validation("EMPTY_CART_ITEMS", "cart_items cannot be empty")
 	}
 
-	cart := &models.Cart{
-		UserID: request.UserID,
-	}
-	if err := s.repo.CreateCart(cart); err != nil {
-		logrus.Errorf("create cart error: %v", err)
+	if err := s.checkRestaurantOpen(ctx, request.CartItemRequests, request.ScheduledFor); err != nil {
 		return nil, err
 	}
 
-	cartItems := []*models.CartItem{}
-	for _, req := range request.CartItemRequests {
-		if req.Quantity <= 0 {
-			return nil, errors.New("quantity must be more than 0")
+	cart := &models.Cart{
+		UserID: request.UserID,
+	}
+	err = s.events.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateCart(ctx, cart); err != nil {
+			logrus.WithContext(ctx).Errorf("create cart error: %v", err)
+			return err
 		}
 
-		cartItem := &models.CartItem{
-			CartID:    cart.ID,
-			ProductID: req.ProductID,
-			Quantity:  req.Quantity,
+		cartItems := []*models.CartItem{}
+		for _, req := range request.CartItemRequests {
+			if req.Quantity <= 0 {
+				return apperror.Validation("INVALID_QUANTITY", "quantity must be more than 0")
+			}
+
+			cartItem := &models.CartItem{
+				CartID:    cart.ID,
+				ProductID: req.ProductID,
+				Quantity:  req.Quantity,
+			}
+
+			if err := s.CalculateCartItem(ctx, cartItem); err != nil {
+				logrus.WithContext(ctx).Errorf("calculate cart item error: %v", err)
+				return err
+			}
+
+			cartItems = append(cartItems, cartItem)
 		}
 
-		if err := s.CalculateCartItem(cartItem); err != nil {
-			logrus.Errorf("calculate cart item error: %v", err)
-			return nil, err
+		for _, item := range cartItems {
+			if err := s.repo.CreateCartItem(ctx, item); err != nil {
+				logrus.WithContext(ctx).Errorf("crate cart item error: %v", err)
+				return err
+			}
 		}
 
-		cartItems = append(cartItems, cartItem)
-	}
+		cart.CartItems = cartItems
 
-	for _, item := range cartItems {
-		if err := s.repo.CreateCartItem(item); err != nil {
-			logrus.Errorf("crate cart item error: %v", err)
-			return nil, err
+		if err := s.CalculateCart(ctx, cart); err != nil {
+			logrus.WithContext(ctx).Errorf("calculate cart error: %v", err)
+			return err
 		}
-	}
 
-	cart.CartItems = cartItems
+		if err := s.repo.UpdateCart(ctx, cart); err != nil {
+			logrus.WithContext(ctx).Errorf("update cart error: %v", err)
+			return err
+		}
 
-	if err := s.CalculateCart(cart); err != nil {
-		logrus.Errorf("calculate cart error: %v", err)
+		return s.events.Record(ctx, &events.CartUpdated{CartID: cart.ID, UserID: cart.UserID, ItemCount: len(cart.CartItems)})
+	})
+	if err != nil {
 		return nil, err
 	}
+	metrics.CartsCreated.Inc()
 
-	if err := s.repo.UpdateCart(cart); err != nil {
-		logrus.Errorf("update cart error: %v", err)
+	s.repo.Preload(ctx, cart)
+	if err := s.EstimateCart(ctx, cart, nil, nil); err != nil {
 		return nil, err
 	}
-
-	s.repo.Preload(cart)
 	return cart, nil
 }
 
-func (s *service) Update(c *fiber.Ctx, request *UpdateRequest) (*models.Cart, error) {
-	cart, err := s.repo.FindCartByUserID(request.UserID)
+func (s *service) Update(ctx context.Context, request *UpdateRequest) (*models.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.Update")
+	defer span.End()
+
+	cart, err := s.repo.FindCartByUserID(ctx, request.UserID)
 	if err != nil {
-		logrus.Errorf("find cart error: %v", err)
-		return nil, errors.New("cart not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("CART_NOT_FOUND", "cart not found")
+		}
+		logrus.WithContext(ctx).Errorf("find cart error: %v", err)
+		return nil, err
 	}
 
 	if len(request.CartItemRequests) == 0 {
-		return nil, errors.New("cart_items cannot be empty")
+		return nil, apperror.Validation("EMPTY_CART_ITEMS", "cart_items cannot be empty")
 	}
 
-	if err := s.repo.DeleteAllCartItems(cart.ID); err != nil {
-		logrus.Errorf("delete all cart items error: %v", err)
+	if err := s.checkRestaurantOpen(ctx, request.CartItemRequests, request.ScheduledFor); err != nil {
 		return nil, err
 	}
 
-	cartItems := []*models.CartItem{}
-	for _, req := range request.CartItemRequests {
-		if req.Quantity <= 0 {
-			// ถ้าจำนวนเป็น 0 หรือ น้อยกว่า ให้ข้ามไป (ไม่เพิ่มสินค้านี้)
-			continue
+	err = s.events.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteAllCartItems(ctx, cart.ID); err != nil {
+			logrus.WithContext(ctx).Errorf("delete all cart items error: %v", err)
+			return err
 		}
 
-		cartItem := &models.CartItem{
-			CartID:    cart.ID,
-			ProductID: req.ProductID,
-			Quantity:  req.Quantity,
+		cartItems := []*models.CartItem{}
+		for _, req := range request.CartItemRequests {
+			if req.Quantity <= 0 {
+				// ถ้าจำนวนเป็น 0 หรือ น้อยกว่า ให้ข้ามไป (ไม่เพิ่มสินค้านี้)
+				continue
+			}
+
+			cartItem := &models.CartItem{
+				CartID:    cart.ID,
+				ProductID: req.ProductID,
+				Quantity:  req.Quantity,
+			}
+
+			if err := s.CalculateCartItem(ctx, cartItem); err != nil {
+				logrus.WithContext(ctx).Errorf("calculate cart item error: %v", err)
+				return err
+			}
+
+			cartItems = append(cartItems, cartItem)
 		}
 
-		if err := s.CalculateCartItem(cartItem); err != nil {
-			logrus.Errorf("calculate cart item error: %v", err)
-			return nil, err
+		for _, item := range cartItems {
+			if err := s.repo.CreateCartItem(ctx, item); err != nil {
+				logrus.WithContext(ctx).Errorf("crate cart item error: %v", err)
+				return err
+			}
 		}
 
-		cartItems = append(cartItems, cartItem)
-	}
+		cart.CartItems = cartItems
 
-	for _, item := range cartItems {
-		if err := s.repo.CreateCartItem(item); err != nil {
-			logrus.Errorf("crate cart item error: %v", err)
-			return nil, err
+		if err := s.CalculateCart(ctx, cart); err != nil {
+			logrus.WithContext(ctx).Errorf("calculate cart error: %v", err)
+			return err
 		}
-	}
 
-	cart.CartItems = cartItems
+		if err := s.repo.UpdateCart(ctx, cart); err != nil {
+			logrus.WithContext(ctx).Errorf("update cart error: %v", err)
+			return err
+		}
 
-	if err := s.CalculateCart(cart); err != nil {
-		logrus.Errorf("calculate cart error: %v", err)
+		return s.events.Record(ctx, &events.CartUpdated{CartID: cart.ID, UserID: cart.UserID, ItemCount: len(cart.CartItems)})
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := s.repo.UpdateCart(cart); err != nil {
-		logrus.Errorf("update cart error: %v", err)
+	s.repo.Preload(ctx, cart)
+	if err := s.EstimateCart(ctx, cart, nil, nil); err != nil {
 		return nil, err
 	}
-
-	s.repo.Preload(cart)
 	return cart, nil
 }
 
-func (s *service) ApplyPromotion(c *fiber.Ctx, request *PromotionRequest) (*models.Cart, error) {
-	cart, err := s.repo.FindCartByUserID(request.UserID)
+func (s *service) ApplyPromotion(ctx context.Context, request *PromotionRequest) (*models.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.ApplyPromotion")
+	defer span.End()
+
+	cart, err := s.repo.FindCartByUserID(ctx, request.UserID)
 	if err != nil {
-		logrus.Errorf("find cart error: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("CART_NOT_FOUND", "cart not found")
+		}
+		logrus.WithContext(ctx).Errorf("find cart error: %v", err)
 		return nil, err
 	}
 
-	promotion, err := s.promoRepo.FindPromotionByCode(request.PromotionCode)
+	promotion, err := s.promoRepo.FindPromotionByCode(ctx, request.PromotionCode)
 	if err != nil {
-		logrus.Errorf("find promotion error: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("PROMOTION_NOT_FOUND", "promotion not found")
+		}
+		logrus.WithContext(ctx).Errorf("find promotion error: %v", err)
 		return nil, err
 	}
 
 	var isValidPromotion bool
 	for _, item := range cart.CartItems {
-		if err := s.CalculateCartItem(item); err != nil {
-			logrus.Errorf("calculate cart item error: %v", err)
+		if err := s.CalculateCartItem(ctx, item); err != nil {
+			logrus.WithContext(ctx).Errorf("calculate cart item error: %v", err)
 			return nil, err
 		}
 
@@ -210,28 +322,45 @@ func (s *service) ApplyPromotion(c *fiber.Ctx, request *PromotionRequest) (*mode
 	}
 
 	if !isValidPromotion {
-		return nil, errors.New("promotion is not applicable for items in the cart")
+		return nil, apperror.Validation("PROMOTION_NOT_APPLICABLE", "promotion is not applicable for items in the cart")
 	}
 
-	if err := s.CalculateCart(cart); err != nil {
-		logrus.Errorf("calculate cart error: %v", err)
+	if err := s.CalculateCart(ctx, cart); err != nil {
+		logrus.WithContext(ctx).Errorf("calculate cart error: %v", err)
 		return nil, err
 	}
 
 	cart.PromotionID = &promotion.ID
-	if err := s.repo.UpdateCart(cart); err != nil {
-		logrus.Errorf("update cart error: %v", err)
+	err = s.events.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateCart(ctx, cart); err != nil {
+			logrus.WithContext(ctx).Errorf("update cart error: %v", err)
+			return err
+		}
+
+		return s.events.Record(ctx, &events.PromotionApplied{CartID: cart.ID, UserID: cart.UserID, PromotionID: promotion.ID, Discount: cart.Discount})
+	})
+	if err != nil {
 		return nil, err
 	}
+	metrics.PromotionsApplied.Inc()
 
-	s.repo.Preload(cart)
+	s.repo.Preload(ctx, cart)
+	if err := s.EstimateCart(ctx, cart, nil, nil); err != nil {
+		return nil, err
+	}
 	return cart, nil
 }
 
-func (s *service) GetAllCart(c *fiber.Ctx, request *GetAllRequests) (*models.Cart, error) {
-	cart, err := s.repo.FindCartByUserID(request.UserID)
+func (s *service) GetAllCart(ctx context.Context, request *GetAllRequests) (*models.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.GetAllCart")
+	defer span.End()
+
+	cart, err := s.repo.FindCartByUserID(ctx, request.UserID)
 	if err != nil {
-		logrus.Errorf("find cart error: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("CART_NOT_FOUND", "cart not found")
+		}
+		logrus.WithContext(ctx).Errorf("find cart error: %v", err)
 		return nil, err
 	}
 
@@ -249,57 +378,81 @@ func (s *service) GetAllCart(c *fiber.Ctx, request *GetAllRequests) (*models.Car
 	cart.SubTotal = totalAmount
 	cart.Total = totalAmount - cart.Discount
 
+	if err := s.EstimateCart(ctx, cart, request.Latitude, request.Longitude); err != nil {
+		return nil, err
+	}
+
 	return cart, nil
 }
 
-func (s *service) RemoveItem(c *fiber.Ctx, request *RemoveItemRequest) (*models.Cart, error) {
-	cart, err := s.repo.FindCartByUserID(request.UserID)
+func (s *service) RemoveItem(ctx context.Context, request *RemoveItemRequest) (*models.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.RemoveItem")
+	defer span.End()
+
+	cart, err := s.repo.FindCartByUserID(ctx, request.UserID)
 	if err != nil {
-		logrus.Errorf("find cart error: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("CART_NOT_FOUND", "cart not found")
+		}
+		logrus.WithContext(ctx).Errorf("find cart error: %v", err)
 		return nil, err
 	}
-	if _, err := s.repo.FindCartItem(cart.ID, request.ProductID); err != nil {
+	if _, err := s.repo.FindCartItem(ctx, cart.ID, request.ProductID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("cart item not found")
+			return nil, apperror.NotFound("CART_ITEM_NOT_FOUND", "cart item not found")
 		}
-		logrus.Errorf("find cart item error: %v", err)
+		logrus.WithContext(ctx).Errorf("find cart item error: %v", err)
 		return nil, err
 	}
 
-	if err := s.repo.DeleteCartItem(cart.ID, request.ProductID); err != nil {
-		logrus.Errorf("delete cart item error: %v", err)
-		return nil, err
-	}
+	var remainingItems int64
+	err = s.events.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteCartItem(ctx, cart.ID, request.ProductID); err != nil {
+			logrus.WithContext(ctx).Errorf("delete cart item error: %v", err)
+			return err
+		}
 
-	cartItems, err := s.repo.FindCartItemsByCartID(cart.ID)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		logrus.Errorf("find cart items error: %v", err)
-		return nil, err
-	}
+		cartItems, err := s.repo.FindCartItemsByCartID(ctx, cart.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			logrus.WithContext(ctx).Errorf("find cart items error: %v", err)
+			return err
+		}
 
-	var newSubTotal float64
-	for _, item := range cartItems {
-		newSubTotal += item.TotalPrice
-	}
-	cart.SubTotal = newSubTotal
-	cart.Total = newSubTotal
+		var newSubTotal float64
+		for _, item := range cartItems {
+			newSubTotal += item.TotalPrice
+		}
+		cart.SubTotal = newSubTotal
+		cart.Total = newSubTotal
+
+		remainingItems, err = s.repo.CountCartItems(ctx, cart.ID)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("count cart items error: %v", err)
+			return err
+		}
+
+		if remainingItems == 0 {
+			if err := s.repo.DeleteCart(ctx, cart.ID); err != nil {
+				logrus.WithContext(ctx).Errorf("delete cart error: %v", err)
+				return err
+			}
+		}
 
-	remainingItems, err := s.repo.CountCartItems(cart.ID)
+		return s.events.Record(ctx, &events.CartUpdated{CartID: cart.ID, UserID: cart.UserID, ItemCount: int(remainingItems)})
+	})
 	if err != nil {
-		logrus.Errorf("count cart items error: %v", err)
 		return nil, err
 	}
-
 	if remainingItems == 0 {
-		if err := s.repo.DeleteCart(cart.ID); err != nil {
-			logrus.Errorf("delete cart error: %v", err)
-			return nil, err
-		}
 		return nil, nil
 	}
 
-	if err := s.repo.Preload(cart); err != nil {
-		logrus.Errorf("preload cart error: %v", err)
+	if err := s.repo.Preload(ctx, cart); err != nil {
+		logrus.WithContext(ctx).Errorf("preload cart error: %v", err)
+		return nil, err
+	}
+
+	if err := s.EstimateCart(ctx, cart, nil, nil); err != nil {
 		return nil, err
 	}
 	
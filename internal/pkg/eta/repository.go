@@ -0,0 +1,45 @@
+package eta
+
+import (
+	"context"
+	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	FindRestaurantByID(ctx context.Context, id uint) (*models.Restaurant, error)
+	CountQueuedOrders(ctx context.Context, restaurantID uint, before time.Time) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindRestaurantByID(ctx context.Context, id uint) (*models.Restaurant, error) {
+	restaurant := &models.Restaurant{}
+	if err := database.Conn(ctx, r.db).Where("id = ?", id).First(restaurant).Error; err != nil {
+		return nil, err
+	}
+	return restaurant, nil
+}
+
+// CountQueuedOrders counts orders the kitchen still has to cook that were
+// placed before the given time.
+func (r *repository) CountQueuedOrders(ctx context.Context, restaurantID uint, before time.Time) (int64, error) {
+	var count int64
+	err := database.Conn(ctx, r.db).Model(&models.Order{}).
+		Where("restaurant_id = ? AND status IN ? AND created_at < ?", restaurantID,
+			[]string{models.OrderStatusPlaced, models.OrderStatusPreparing}, before).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
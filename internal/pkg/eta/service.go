@@ -0,0 +1,190 @@
+package eta
+
+import (
+	"context"
+	"errors"
+	"food-delivery-workshop/internal/core/tracing"
+	"food-delivery-workshop/internal/geo"
+	"food-delivery-workshop/internal/models"
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	DefaultPrepMinutes   uint    // ใช้เมื่อทั้งสินค้าและร้านไม่ได้กำหนดเวลาเตรียม
+	QueueMinutesPerOrder int     // เวลาที่เพิ่มต่อหนึ่งออเดอร์ที่รออยู่ในครัว
+	PickupMinutes        int     // เวลารอไรเดอร์มารับเมื่อยังไม่มีไรเดอร์
+	DefaultDistanceKm    float64 // ใช้เมื่อยังไม่รู้ตำแหน่งจัดส่ง
+	RiderSpeedKmh        float64
+	RangeMinutes         int // ความกว้างของช่วงเวลาที่แสดง
+}
+
+func DefaultConfig() Config {
+	return Config{
+		DefaultPrepMinutes:   15,
+		QueueMinutesPerOrder: 5,
+		PickupMinutes:        10,
+		DefaultDistanceKm:    3,
+		RiderSpeedKmh:        25,
+		RangeMinutes:         10,
+	}
+}
+
+type Service interface {
+	EstimateCart(ctx context.Context, cart *models.Cart, latitude, longitude *float64) (*models.ETA, error)
+	EstimateOrder(ctx context.Context, order *models.Order) (*models.ETA, error)
+}
+
+type service struct {
+	repo   Repository
+	config Config
+}
+
+func NewService(repo Repository, config Config) Service {
+	return &service{repo: repo, config: config}
+}
+
+// EstimateCart estimates delivery for a cart that would be checked out now.
+// It returns nil when the cart has no products linked to a restaurant.
+func (s *service) EstimateCart(ctx context.Context, cart *models.Cart, latitude, longitude *float64) (*models.ETA, error) {
+	ctx, span := tracing.Start(ctx, "eta.EstimateCart")
+	defer span.End()
+
+	var restaurantID *uint
+	products := []*models.Product{}
+	for _, item := range cart.CartItems {
+		if item.Product == nil || item.Product.RestaurantID == nil {
+			continue
+		}
+		restaurantID = item.Product.RestaurantID
+		products = append(products, item.Product)
+	}
+	if restaurantID == nil {
+		return nil, nil
+	}
+
+	restaurant, err := s.repo.FindRestaurantByID(ctx, *restaurantID)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find restaurant error: %v", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	queued, err := s.repo.CountQueuedOrders(ctx, restaurant.ID, now)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("count queued orders error: %v", err)
+		return nil, err
+	}
+
+	distance := s.config.DefaultDistanceKm
+	if latitude != nil && longitude != nil {
+		distance = geo.DistanceKm(restaurant.Latitude, restaurant.Longitude, *latitude, *longitude)
+	}
+
+	minutes := s.prepMinutes(restaurant, products) +
+		int(queued)*s.config.QueueMinutesPerOrder +
+		s.config.PickupMinutes +
+		s.travelMinutes(distance)
+	return s.newETA(now, minutes), nil
+}
+
+// EstimateOrder estimates the remaining time for an order based on its
+// current status. The order must have Restaurant, OrderItems.Product and
+// Rider preloaded.
+func (s *service) EstimateOrder(ctx context.Context, order *models.Order) (*models.ETA, error) {
+	ctx, span := tracing.Start(ctx, "eta.EstimateOrder")
+	defer span.End()
+
+	if order.Restaurant == nil {
+		return nil, errors.New("order restaurant is not loaded")
+	}
+	restaurant := order.Restaurant
+	deliveryDistance := geo.DistanceKm(restaurant.Latitude, restaurant.Longitude, order.DeliveryLatitude, order.DeliveryLongitude)
+
+	var minutes int
+	switch order.Status {
+	case models.OrderStatusScheduled:
+		// สั่งล่วงหน้า ใช้เวลาที่ลูกค้าเลือกเป็นช่วงเวลาส่ง
+		if order.ScheduledFor == nil {
+			return &models.ETA{}, nil
+		}
+		minutes = int(math.Ceil(time.Until(*order.ScheduledFor).Minutes()))
+	case models.OrderStatusPlaced:
+		queued, err := s.repo.CountQueuedOrders(ctx, restaurant.ID, order.CreatedAt)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("count queued orders error: %v", err)
+			return nil, err
+		}
+		minutes = s.prepMinutes(restaurant, orderProducts(order)) +
+			int(queued)*s.config.QueueMinutesPerOrder +
+			s.pickupMinutes(ctx, order) +
+			s.travelMinutes(deliveryDistance)
+	case models.OrderStatusPreparing:
+		minutes = s.prepMinutes(restaurant, orderProducts(order)) +
+			s.pickupMinutes(ctx, order) +
+			s.travelMinutes(deliveryDistance)
+	case models.OrderStatusReady:
+		minutes = s.pickupMinutes(ctx, order) + s.travelMinutes(deliveryDistance)
+	case models.OrderStatusPickedUp:
+		if order.Rider != nil && order.Rider.LocationUpdatedAt != nil {
+			deliveryDistance = geo.DistanceKm(order.Rider.Latitude, order.Rider.Longitude, order.DeliveryLatitude, order.DeliveryLongitude)
+		}
+		minutes = s.travelMinutes(deliveryDistance)
+	default:
+		// ส่งถึงแล้วหรือยกเลิก ไม่มีเวลาที่ต้องรอ
+		return &models.ETA{}, nil
+	}
+
+	return s.newETA(time.Now(), minutes), nil
+}
+
+func (s *service) prepMinutes(restaurant *models.Restaurant, products []*models.Product) int {
+	base := restaurant.PrepMinutes
+	if base == 0 {
+		base = s.config.DefaultPrepMinutes
+	}
+
+	// ครัวทำหลายเมนูพร้อมกัน จึงใช้เมนูที่นานที่สุด
+	longest := base
+	for _, product := range products {
+		if product.PrepMinutes > longest {
+			longest = product.PrepMinutes
+		}
+	}
+	return int(longest)
+}
+
+func (s *service) pickupMinutes(ctx context.Context, order *models.Order) int {
+	if order.Rider == nil || order.Rider.LocationUpdatedAt == nil {
+		return s.config.PickupMinutes
+	}
+	distance := geo.DistanceKm(order.Rider.Latitude, order.Rider.Longitude, order.Restaurant.Latitude, order.Restaurant.Longitude)
+	return s.travelMinutes(distance)
+}
+
+func (s *service) travelMinutes(distanceKm float64) int {
+	return int(math.Ceil(distanceKm / s.config.RiderSpeedKmh * 60))
+}
+
+func (s *service) newETA(from time.Time, minutes int) *models.ETA {
+	earliest := from.Add(time.Duration(minutes) * time.Minute)
+	latest := earliest.Add(time.Duration(s.config.RangeMinutes) * time.Minute)
+	return &models.ETA{
+		MinMinutes: minutes,
+		MaxMinutes: minutes + s.config.RangeMinutes,
+		EarliestAt: &earliest,
+		LatestAt:   &latest,
+	}
+}
+
+func orderProducts(order *models.Order) []*models.Product {
+	products := []*models.Product{}
+	for _, item := range order.OrderItems {
+		if item.Product != nil {
+			products = append(products, item.Product)
+		}
+	}
+	return products
+}
@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type StatusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Liveness Liveness probe
+// @Summary Liveness probe
+// @Description Answers 200 while the process is running. It does not check dependencies, so a database outage does not restart the pod.
+// @Tags health
+// @Produce  json
+// @Success 200 {object} StatusResponse
+// @Router /healthz [get]
+func Liveness(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(&StatusResponse{Status: "ok"})
+}
+
+// Readiness Readiness probe
+// @Summary Readiness probe
+// @Description Answers 200 when the database is reachable and migrated, otherwise 503 so the pod is taken out of the load balancer.
+// @Tags health
+// @Produce  json
+// @Success 200 {object} StatusResponse
+// @Failure 503 {object} StatusResponse
+// @Router /readyz [get]
+func Readiness(c *fiber.Ctx, service Service) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+	defer cancel()
+
+	if err := service.Ready(ctx); err != nil {
+		logrus.WithContext(ctx).Errorf("readiness check error: %v", err)
+		return c.Status(fiber.StatusServiceUnavailable).JSON(&StatusResponse{Status: "unavailable", Error: err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(&StatusResponse{Status: "ok"})
+}
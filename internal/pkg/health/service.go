@@ -0,0 +1,30 @@
+package health
+
+import (
+	"context"
+	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/core/tracing"
+
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	Ready(ctx context.Context) error
+}
+
+type service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) Service {
+	return &service{db: db}
+}
+
+// Ready checks the dependencies needed to serve traffic: the database is
+// reachable and migrated.
+func (s *service) Ready(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "health.Ready")
+	defer span.End()
+
+	return database.Check(ctx, s.db)
+}
@@ -0,0 +1,223 @@
+package order
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/validation"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Checkout Place an order from the cart
+// @Summary Checkout
+// @Description Place an order from the current cart and clear the cart. Set scheduled_for to a slot from /restaurants/{id}/slots to pre-order.
+// @Tags order
+// @Accept  json
+// @Produce  json
+// @Param request body CheckoutRequest true "Checkout request"
+// @Param Idempotency-Key header string false "Retries with the same key replay the first response"
+// @Success 201 {object} models.Order
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /orders [post]
+func Checkout(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := validation.BindAndValidate[CheckoutRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.UserID = principal.UserID
+	order, err := service.Checkout(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(order)
+}
+
+// UpdateStatus Update order status
+// @Summary Update order status
+// @Description Move an order to its next status (placed, preparing, ready, picked_up, delivered, cancelled). Requires the staff or admin role, or an API key with orders:write
+// @Tags order
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Param request body UpdateStatusRequest true "Status request"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /orders/{id}/status [put]
+func UpdateStatus(c *fiber.Ctx, service Service) error {
+	orderID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid order ID")
+	}
+
+	request, err := validation.BindAndValidate[UpdateStatusRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.ID = uint(orderID)
+	order, err := service.UpdateStatus(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// GetOrderByID Get order
+// @Summary Get order by id
+// @Description Get one of the current user's orders
+// @Tags order
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /orders/{id} [get]
+func GetOrderByID(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	orderID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid order ID")
+	}
+
+	order, err := service.GetOrderByID(c.UserContext(), &GetRequest{ID: uint(orderID), UserID: principal.UserID})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+// GetAllOrder Get orders
+// @Summary Get all orders
+// @Description Get the current user's orders, newest first
+// @Tags order
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Order
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /orders [get]
+func GetAllOrder(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	orders, err := service.GetAllOrders(c.UserContext(), &GetAllRequests{UserID: principal.UserID})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(orders)
+}
+
+// Stream Stream order events
+// @Summary Stream order tracking events
+// @Description Server-Sent Events stream of order status changes and rider location updates. The token may also be passed as the access_token query parameter for EventSource clients.
+// @Tags order
+// @Produce text/event-stream
+// @Param id path int true "Order ID"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /orders/{id}/stream [get]
+func Stream(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	orderID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid order ID")
+	}
+
+	order, sub, err := service.Subscribe(c.UserContext(), &GetRequest{ID: uint(orderID), UserID: principal.UserID})
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		initial := &StatusEvent{OrderID: order.ID, Status: order.Status, ETA: &order.ETA, UpdatedAt: order.UpdatedAt}
+		if err := writeEvent(w, EventStatusChanged, initial); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case msg, ok := <-sub.C:
+				if !ok {
+					// ถูกตัดออกเพราะอ่านไม่ทัน ให้ client reconnect แล้วรับสถานะล่าสุดใหม่
+					return
+				}
+				if err := writeEvent(w, msg.Event, msg.Data); err != nil {
+					return
+				}
+			case now := <-heartbeat.C:
+				if err := writeEvent(w, "ping", fiber.Map{"time": now}); err != nil {
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+const heartbeatInterval = 15 * time.Second
+
+func writeEvent(w *bufio.Writer, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
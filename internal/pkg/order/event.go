@@ -0,0 +1,33 @@
+package order
+
+import (
+	"fmt"
+	"food-delivery-workshop/internal/models"
+	"time"
+)
+
+const (
+	EventStatusChanged = "order.status_changed"
+	EventRiderAssigned = "rider.assigned"
+	EventRiderLocation = "rider.location"
+)
+
+type StatusEvent struct {
+	OrderID   uint        `json:"order_id"`
+	Status    string      `json:"status"`
+	ETA       *models.ETA `json:"eta"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type RiderEvent struct {
+	OrderID   uint      `json:"order_id"`
+	RiderID   uint      `json:"rider_id"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Topic is the pub/sub topic that carries all events of one order.
+func Topic(orderID uint) string {
+	return fmt.Sprintf("order:%d", orderID)
+}
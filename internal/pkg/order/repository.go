@@ -0,0 +1,153 @@
+package order
+
+import (
+	"context"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	Create(ctx context.Context, order *models.Order) error
+	Update(ctx context.Context, order *models.Order) error
+	FindByID(ctx context.Context, id uint) (*models.Order, error)
+	FindByUserID(ctx context.Context, userID uint) ([]*models.Order, error)
+	FindReadyUnassigned(ctx context.Context) ([]*models.Order, error)
+	FindActiveByRiderID(ctx context.Context, riderID uint) ([]*models.Order, error)
+	FindDueScheduled(ctx context.Context, now time.Time) ([]*models.Order, error)
+	AssignRider(ctx context.Context, orderID uint, riderID uint) error
+	FindIDsNeedingRotation(ctx context.Context, needsRotation func(value string) bool) ([]uint, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, order *models.Order) error {
+	if err := database.Conn(ctx, r.db).Create(order).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) Update(ctx context.Context, order *models.Order) error {
+	if err := database.Conn(ctx, r.db).Omit(clause.Associations).Save(order).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindByID(ctx context.Context, id uint) (*models.Order, error) {
+	order := &models.Order{}
+	err := database.Conn(ctx, r.db).Preload("OrderItems.Product").
+		Preload("Restaurant").
+		Preload("Rider").
+		Where("id = ?", id).First(order).Error
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (r *repository) FindByUserID(ctx context.Context, userID uint) ([]*models.Order, error) {
+	var orders []*models.Order
+	err := database.Conn(ctx, r.db).Preload("OrderItems.Product").
+		Preload("Restaurant").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *repository) FindReadyUnassigned(ctx context.Context) ([]*models.Order, error) {
+	var orders []*models.Order
+	err := database.Conn(ctx, r.db).Preload("Restaurant").
+		Where("status = ? AND rider_id IS NULL", models.OrderStatusReady).
+		Order("updated_at").Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *repository) FindActiveByRiderID(ctx context.Context, riderID uint) ([]*models.Order, error) {
+	var orders []*models.Order
+	err := database.Conn(ctx, r.db).Where("rider_id = ? AND status IN ?", riderID,
+		[]string{models.OrderStatusReady, models.OrderStatusPickedUp}).Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *repository) FindDueScheduled(ctx context.Context, now time.Time) ([]*models.Order, error) {
+	var orders []*models.Order
+	err := database.Conn(ctx, r.db).Preload("OrderItems.Product").
+		Preload("Restaurant").
+		Preload("Rider").
+		Where("status = ? AND release_at <= ?", models.OrderStatusScheduled, now).
+		Order("release_at").Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// AssignRider sets the rider only if the order is still unassigned, so two
+// riders accepting at the same time cannot both win the order.
+func (r *repository) AssignRider(ctx context.Context, orderID uint, riderID uint) error {
+	result := database.Conn(ctx, r.db).Model(&models.Order{}).
+		Where("id = ? AND rider_id IS NULL", orderID).
+		Update("rider_id", riderID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperror.Conflict("ORDER_ALREADY_ASSIGNED", "order already assigned")
+	}
+	return nil
+}
+
+// FindIDsNeedingRotation reads the raw delivery_address column and returns
+// orders whose value needsRotation reports as stale.
+func (r *repository) FindIDsNeedingRotation(ctx context.Context, needsRotation func(value string) bool) ([]uint, error) {
+	type piiColumns struct {
+		ID              uint
+		DeliveryAddress string
+	}
+
+	ids := []uint{}
+	var lastID uint
+	for {
+		rows := []piiColumns{}
+		err := database.Conn(ctx, r.db).Model(&models.Order{}).
+			Select("id, delivery_address").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(500).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return ids, nil
+		}
+
+		for _, row := range rows {
+			if needsRotation(row.DeliveryAddress) {
+				ids = append(ids, row.ID)
+			}
+		}
+		lastID = rows[len(rows)-1].ID
+	}
+}
@@ -0,0 +1,25 @@
+package order
+
+import "time"
+
+type CheckoutRequest struct {
+	UserID            uint       `json:"-"`
+	DeliveryAddress   string     `json:"delivery_address" validate:"required"`
+	DeliveryLatitude  float64    `json:"delivery_latitude" validate:"required,latitude"`
+	DeliveryLongitude float64    `json:"delivery_longitude" validate:"required,longitude"`
+	ScheduledFor      *time.Time `json:"scheduled_for"` // เว้นว่างเพื่อส่งทันที
+}
+
+type UpdateStatusRequest struct {
+	ID     uint   `json:"-" path:"id"`
+	Status string `json:"status" validate:"required,oneof=placed preparing ready picked_up delivered cancelled"`
+}
+
+type GetRequest struct {
+	ID     uint `json:"-" path:"id"`
+	UserID uint `json:"-"`
+}
+
+type GetAllRequests struct {
+	UserID uint `json:"-"`
+}
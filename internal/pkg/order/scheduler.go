@@ -0,0 +1,29 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StartScheduler runs ReleaseScheduledOrders every interval in the background until ctx is
+// cancelled. Each run gets at most one interval to finish.
+func StartScheduler(ctx context.Context, service Service, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				runCtx, cancel := context.WithTimeout(ctx, interval)
+				if err := service.ReleaseScheduledOrders(runCtx); err != nil {
+					logrus.WithContext(ctx).Errorf("release scheduled orders error: %v", err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
@@ -0,0 +1,332 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/events"
+	"food-delivery-workshop/internal/core/metrics"
+	"food-delivery-workshop/internal/core/pii"
+	"food-delivery-workshop/internal/core/pubsub"
+	"food-delivery-workshop/internal/core/tracing"
+	"food-delivery-workshop/internal/models"
+	"food-delivery-workshop/internal/pkg/cart"
+	"food-delivery-workshop/internal/pkg/eta"
+	"food-delivery-workshop/internal/pkg/restaurant"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	Checkout(ctx context.Context, request *CheckoutRequest) (*models.Order, error)
+	UpdateStatus(ctx context.Context, request *UpdateStatusRequest) (*models.Order, error)
+	GetOrderByID(ctx context.Context, request *GetRequest) (*models.Order, error)
+	GetAllOrders(ctx context.Context, request *GetAllRequests) ([]*models.Order, error)
+	Subscribe(ctx context.Context, request *GetRequest) (*models.Order, *pubsub.Subscription, error)
+	ReleaseScheduledOrders(ctx context.Context) error
+	RotatePII(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repo              Repository
+	cartService       cart.Service
+	cartRepo          cart.Repository
+	restaurantService restaurant.Service
+	etaService        eta.Service
+	hub               *pubsub.Hub
+	events            events.Recorder
+}
+
+func NewService(repo Repository, cartService cart.Service, cartRepo cart.Repository, restaurantService restaurant.Service, etaService eta.Service, hub *pubsub.Hub, recorder events.Recorder) Service {
+	return &service{repo: repo, cartService: cartService, cartRepo: cartRepo, restaurantService: restaurantService, etaService: etaService, hub: hub, events: recorder}
+}
+
+func (s *service) Checkout(ctx context.Context, request *CheckoutRequest) (*models.Order, error) {
+	ctx, span := tracing.Start(ctx, "order.Checkout")
+	defer span.End()
+
+	userCart, err := s.cartService.GetAllCart(ctx, &cart.GetAllRequests{UserID: request.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(userCart.CartItems) == 0 {
+		return nil, apperror.Validation("CART_EMPTY", "cart is empty")
+	}
+
+	var restaurantID *uint
+	orderItems := []*models.OrderItem{}
+	for _, item := range userCart.CartItems {
+		if item.Product == nil || item.Product.RestaurantID == nil {
+			return nil, apperror.Validation("PRODUCT_WITHOUT_RESTAURANT", "product is not assigned to a restaurant")
+		}
+		if restaurantID != nil && *restaurantID != *item.Product.RestaurantID {
+			return nil, apperror.Validation("MIXED_RESTAURANTS", "cart items must be from the same restaurant")
+		}
+		restaurantID = item.Product.RestaurantID
+
+		orderItems = append(orderItems, &models.OrderItem{
+			ProductID:  item.ProductID,
+			Quantity:   item.Quantity,
+			Price:      item.Price,
+			TotalPrice: item.TotalPrice,
+		})
+	}
+
+	status := models.OrderStatusPlaced
+	var releaseAt *time.Time
+	if request.ScheduledFor != nil {
+		// slot ถูกตรวจใน transaction ตอนสร้างออเดอร์ ที่นี่แค่กำหนด status
+		status = models.OrderStatusScheduled
+		release := restaurant.ReleaseAt(*request.ScheduledFor)
+		releaseAt = &release
+	} else if err := s.restaurantService.CheckOpen(ctx, *restaurantID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	order := &models.Order{
+		UserID:            request.UserID,
+		RestaurantID:      *restaurantID,
+		PromotionID:       userCart.PromotionID,
+		Status:            status,
+		DeliveryAddress:   pii.String(request.DeliveryAddress),
+		DeliveryLatitude:  request.DeliveryLatitude,
+		DeliveryLongitude: request.DeliveryLongitude,
+		ScheduledFor:      request.ScheduledFor,
+		ReleaseAt:         releaseAt,
+		SubTotal:          userCart.SubTotal,
+		Discount:          userCart.Discount,
+		Total:             userCart.Total,
+		OrderItems:        orderItems,
+	}
+	// สร้างออเดอร์ ลบตะกร้า และบันทึก event ให้อยู่ใน transaction เดียวกัน
+	err = s.events.Transaction(ctx, func(ctx context.Context) error {
+		// นับที่ว่างและสร้างออเดอร์ภายใต้ lock ของร้าน กันสอง checkout จองที่สุดท้ายพร้อมกัน
+		if order.ScheduledFor != nil {
+			if err := s.restaurantService.ReserveSlot(ctx, order.RestaurantID, *order.ScheduledFor); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.Create(ctx, order); err != nil {
+			logrus.WithContext(ctx).Errorf("create order error: %v", err)
+			return err
+		}
+
+		if err := s.cartRepo.DeleteAllCartItems(ctx, userCart.ID); err != nil {
+			logrus.WithContext(ctx).Errorf("delete all cart items error: %v", err)
+			return err
+		}
+		if err := s.cartRepo.DeleteCart(ctx, userCart.ID); err != nil {
+			logrus.WithContext(ctx).Errorf("delete cart error: %v", err)
+			return err
+		}
+
+		// โหลดออเดอร์พร้อม item และคำนวณ ETA ใน transaction เพื่อให้ event มีข้อมูลครบสำหรับ webhook
+		placed, err := s.repo.FindByID(ctx, order.ID)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("find order error: %v", err)
+			return err
+		}
+		if err := s.updateETA(ctx, placed); err != nil {
+			return err
+		}
+		order = placed
+
+		recorded := []events.Event{&events.OrderPlaced{
+			OrderID:      order.ID,
+			UserID:       order.UserID,
+			RestaurantID: order.RestaurantID,
+			PromotionID:  order.PromotionID,
+			Total:        order.Total,
+			ScheduledFor: order.ScheduledFor,
+			Order:        order,
+		}}
+		if order.PromotionID != nil {
+			recorded = append(recorded, &events.PromotionRedeemed{
+				OrderID:     order.ID,
+				UserID:      order.UserID,
+				PromotionID: *order.PromotionID,
+				Discount:    order.Discount,
+			})
+		}
+		return s.events.Record(ctx, recorded...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.Checkouts.WithLabelValues(strconv.FormatBool(order.ScheduledFor != nil)).Inc()
+
+	return order, nil
+}
+
+func (s *service) UpdateStatus(ctx context.Context, request *UpdateStatusRequest) (*models.Order, error) {
+	ctx, span := tracing.Start(ctx, "order.UpdateStatus")
+	defer span.End()
+
+	order, err := s.repo.FindByID(ctx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("ORDER_NOT_FOUND", "order not found")
+		}
+		logrus.WithContext(ctx).Errorf("find order error: %v", err)
+		return nil, err
+	}
+
+	if !canTransition(order.Status, request.Status) {
+		return nil, apperror.Conflict("INVALID_STATUS_TRANSITION", "invalid status transition from "+order.Status+" to "+request.Status).WithDetails(map[string]interface{}{
+			"from": order.Status,
+			"to":   request.Status,
+		})
+	}
+
+	if request.Status == models.OrderStatusPickedUp && order.RiderID == nil {
+		return nil, apperror.Conflict("RIDER_NOT_ASSIGNED", "order has no rider assigned")
+	}
+
+	if err := s.changeStatus(ctx, order, request.Status); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ReleaseScheduledOrders sends scheduled orders whose release time has come to the kitchen.
+func (s *service) ReleaseScheduledOrders(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "order.ReleaseScheduledOrders")
+	defer span.End()
+
+	orders, err := s.repo.FindDueScheduled(ctx, time.Now())
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find due scheduled orders error: %v", err)
+		return err
+	}
+
+	for _, order := range orders {
+		if err := s.changeStatus(ctx, order, models.OrderStatusPlaced); err != nil {
+			return err
+		}
+		logrus.WithContext(ctx).WithField("order_id", order.ID).Info("scheduled order released to kitchen")
+	}
+	return nil
+}
+
+func (s *service) changeStatus(ctx context.Context, order *models.Order, status string) error {
+	order.Status = status
+	err := s.events.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.updateETA(ctx, order); err != nil {
+			return err
+		}
+		return s.events.Record(ctx, &events.OrderStatusChanged{
+			OrderID:   order.ID,
+			Status:    order.Status,
+			ETA:       &order.ETA,
+			UpdatedAt: order.UpdatedAt,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.hub.Publish(Topic(order.ID), EventStatusChanged, &StatusEvent{
+		OrderID:   order.ID,
+		Status:    order.Status,
+		ETA:       &order.ETA,
+		UpdatedAt: order.UpdatedAt,
+	})
+	return nil
+}
+
+// updateETA re-estimates the delivery time for the order's current status and saves the order.
+func (s *service) updateETA(ctx context.Context, order *models.Order) error {
+	estimate, err := s.etaService.EstimateOrder(ctx, order)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("estimate order error: %v", err)
+		return err
+	}
+
+	order.ETA = *estimate
+	if err := s.repo.Update(ctx, order); err != nil {
+		logrus.WithContext(ctx).Errorf("update order error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (s *service) GetOrderByID(ctx context.Context, request *GetRequest) (*models.Order, error) {
+	ctx, span := tracing.Start(ctx, "order.GetOrderByID")
+	defer span.End()
+
+	order, err := s.repo.FindByID(ctx, request.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("ORDER_NOT_FOUND", "order not found")
+		}
+		logrus.WithContext(ctx).Errorf("find order error: %v", err)
+		return nil, err
+	}
+
+	if order.UserID != request.UserID {
+		return nil, apperror.NotFound("ORDER_NOT_FOUND", "order not found")
+	}
+
+	return order, nil
+}
+
+// Subscribe returns the current order together with a subscription to its
+// status and rider events. The caller must close the subscription.
+func (s *service) Subscribe(ctx context.Context, request *GetRequest) (*models.Order, *pubsub.Subscription, error) {
+	ctx, span := tracing.Start(ctx, "order.Subscribe")
+	defer span.End()
+
+	// subscribe ก่อนอ่านสถานะปัจจุบัน เพื่อไม่ให้พลาด event ที่เกิดระหว่างนั้น
+	sub := s.hub.Subscribe(Topic(request.ID))
+	order, err := s.GetOrderByID(ctx, request)
+	if err != nil {
+		sub.Close()
+		return nil, nil, err
+	}
+
+	return order, sub, nil
+}
+
+func (s *service) GetAllOrders(ctx context.Context, request *GetAllRequests) ([]*models.Order, error) {
+	ctx, span := tracing.Start(ctx, "order.GetAllOrders")
+	defer span.End()
+
+	orders, err := s.repo.FindByUserID(ctx, request.UserID)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find orders error: %v", err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// RotatePII re-encrypts delivery addresses written with an old key or before
+// encryption was enabled. It returns the number of orders updated.
+func (s *service) RotatePII(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "order.RotatePII")
+	defer span.End()
+
+	ids, err := s.repo.FindIDsNeedingRotation(ctx, pii.Current().NeedsRotation)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find orders needing rotation error: %v", err)
+		return 0, err
+	}
+
+	for i, id := range ids {
+		order, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("find order by id error: %v", err)
+			return i, err
+		}
+		if err := s.repo.Update(ctx, order); err != nil {
+			logrus.WithContext(ctx).Errorf("update order error: %v", err)
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,23 @@
+package order
+
+import (
+	"food-delivery-workshop/internal/models"
+)
+
+// สถานะถัดไปที่อนุญาตของแต่ละสถานะ
+var statusTransitions = map[string][]string{
+	models.OrderStatusScheduled: {models.OrderStatusPlaced, models.OrderStatusCancelled},
+	models.OrderStatusPlaced:    {models.OrderStatusPreparing, models.OrderStatusCancelled},
+	models.OrderStatusPreparing: {models.OrderStatusReady, models.OrderStatusCancelled},
+	models.OrderStatusReady:     {models.OrderStatusPickedUp, models.OrderStatusCancelled},
+	models.OrderStatusPickedUp:  {models.OrderStatusDelivered},
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
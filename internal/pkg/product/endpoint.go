@@ -1,8 +1,9 @@
 package product
 
 import (
+	"food-delivery-workshop/internal/apperror"
 	"food-delivery-workshop/internal/get"
-	"net/http"
+	"food-delivery-workshop/internal/validation"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,35 +17,21 @@ import (
 // @Produce  json
 // @Param request body CreateRequest true "Product"
 // @Success 200 {object} models.Product
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
+// @Security ServiceKeyAuth
 // @Router /products [post]
 func Create(c *fiber.Ctx, service Service) error {
-	request := new(CreateRequest)
-	if err := c.BodyParser(request); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
-	}
-
-	if err := validateProductReq(request); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	request, err := validation.BindAndValidate[CreateRequest](c)
+	if err != nil {
+		return err
 	}
 
-	product, err := service.Create(c, request)
+	product, err := service.Create(c.UserContext(), request)
 	if err != nil {
-		if err.Error() == "product name already exist" {
-			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(product)
@@ -59,44 +46,28 @@ func Create(c *fiber.Ctx, service Service) error {
 // @Param id path uint true "Product ID"
 // @Param request body UpdateRequest true "Product data"
 // @Success 200 {object} models.Product
-// @Failure 401 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
+// @Security ServiceKeyAuth
 // @Router /products/{id} [put]
 func Update(c *fiber.Ctx, service Service) error {
 	productIDstr := c.Params("id")
 	productID, err := strconv.ParseUint(productIDstr, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return apperror.Validation("INVALID_ID", "Invalid product ID")
 	}
 
-	request := new(UpdateRequest)
-	if err := c.BodyParser(request); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
-	}
-
-	if err := validateProductReq(request); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	request, err := validation.BindAndValidate[UpdateRequest](c)
+	if err != nil {
+		return err
 	}
 
 	request.ID = uint(productID)
-	updatedProduct, err := service.Update(c, request)
+	updatedProduct, err := service.Update(c.UserContext(), request)
 	if err != nil {
-		if err.Error() == "product not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(updatedProduct)
@@ -110,33 +81,24 @@ func Update(c *fiber.Ctx, service Service) error {
 // @Produce json
 // @Param id path int true "Product ID"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
+// @Security ServiceKeyAuth
 // @Router /products/{id} [delete]
 func Delete(c *fiber.Ctx, service Service) error {
 	productIDstr := c.Params("id")
 	productID, err := strconv.ParseUint(productIDstr, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid product ID",
-		})
+		return apperror.Validation("INVALID_ID", "Invalid product ID")
 	}
 
 	productIDUint := uint(productID)
 	ID := &get.GetOne[uint]{ID: productIDUint}
-	err = service.Delete(c, ID)
-	if err != nil {
-		if err.Error() == "product not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	if err := service.Delete(c.UserContext(), ID); err != nil {
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -150,17 +112,16 @@ func Delete(c *fiber.Ctx, service Service) error {
 // @Accept json
 // @Produce json
 // @Success 200 {array} models.Product
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
+// @Security ServiceKeyAuth
 // @Router /products [get]
 func GetAllProduct(c *fiber.Ctx, service Service) error {
-	products, err := service.GetAllProducts()
+	products, err := service.GetAllProducts(c.UserContext())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error getting products",
-		})
+		return err
 	}
 	return c.Status(fiber.StatusOK).JSON(products)
 }
@@ -172,32 +133,24 @@ func GetAllProduct(c *fiber.Ctx, service Service) error {
 // @Produce json
 // @Param id path int true "Product ID"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
+// @Security ServiceKeyAuth
 // @Router /products/{id} [get]
 func GetProductByID(c *fiber.Ctx, service Service) error {
 	productIDstr := c.Params("id")
 	productID, err := strconv.ParseUint(productIDstr, 10, 0)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid product ID",
-		})
+		return apperror.Validation("INVALID_ID", "Invalid product ID")
 	}
 	productIDUint := uint(productID)
 	ID := &get.GetOne[uint]{ID: productIDUint}
-	product, err := service.GetProductByID(ID)
+	product, err := service.GetProductByID(c.UserContext(), ID)
 	if err != nil {
-		if err.Error() == "product not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Product not found",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error getting product",
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(product)
@@ -1,20 +1,24 @@
 package product
 
 import (
+	"context"
+	"food-delivery-workshop/internal/core/database"
 	"food-delivery-workshop/internal/models"
 
 	"gorm.io/gorm"
 )
 
 type Repository interface {
-	Create(product *models.Product) error
-	Update(product *models.Product) error
-	FindByID(id uint, product *models.Product) error
-	FindAll() ([]models.Product, error)
-	Delete(id uint) error
-	FindByProductName(name string) (*models.Product, error)
-	FindByProductID(productID uint) (*models.Product, error)
-	DeleteCartItemByProductID(productID uint) error
+	Create(ctx context.Context, product *models.Product) error
+	Update(ctx context.Context, product *models.Product) error
+	FindByID(ctx context.Context, id uint, product *models.Product) error
+	FindAll(ctx context.Context) ([]models.Product, error)
+	FindByIDs(ctx context.Context, ids []uint) ([]models.Product, error)
+	FindByRestaurantID(ctx context.Context, restaurantID uint) ([]models.Product, error)
+	Delete(ctx context.Context, id uint) error
+	FindByProductName(ctx context.Context, name string) (*models.Product, error)
+	FindByProductID(ctx context.Context, productID uint) (*models.Product, error)
+	DeleteCartItemByProductID(ctx context.Context, productID uint) error
 }
 
 type repository struct {
@@ -25,59 +29,75 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Preload(product interface{}) error {
-	return r.db.Preload("Promotion").
+func (r *repository) Preload(ctx context.Context, product interface{}) error {
+	return database.Conn(ctx, r.db).Preload("Promotion").
 		Find(product).Error
 }
 
-func (r *repository) Create(product *models.Product) error {
-	if err := r.db.Create(product).Error; err != nil {
+func (r *repository) Create(ctx context.Context, product *models.Product) error {
+	if err := database.Conn(ctx, r.db).Create(product).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *repository) FindByID(id uint, product *models.Product) error {
-	if err := r.db.Where("id = ?", id).First(product).Error; err != nil {
+func (r *repository) FindByID(ctx context.Context, id uint, product *models.Product) error {
+	if err := database.Conn(ctx, r.db).Where("id = ?", id).First(product).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *repository) FindAll() ([]models.Product, error) {
+func (r *repository) FindAll(ctx context.Context) ([]models.Product, error) {
 	var products []models.Product
-	if err := r.db.Where("deleted_at IS NULL").Find(&products).Error; err != nil {
+	if err := database.Conn(ctx, r.db).Where("deleted_at IS NULL").Find(&products).Error; err != nil {
 		return nil, err
 	}
 	return products, nil
 }
 
-func (r *repository) Update(product *models.Product) error {
-	if err := r.db.Save(product).Error; err != nil {
+func (r *repository) FindByIDs(ctx context.Context, ids []uint) ([]models.Product, error) {
+	var products []models.Product
+	if err := database.Conn(ctx, r.db).Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *repository) FindByRestaurantID(ctx context.Context, restaurantID uint) ([]models.Product, error) {
+	var products []models.Product
+	if err := database.Conn(ctx, r.db).Where("restaurant_id = ?", restaurantID).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *repository) Update(ctx context.Context, product *models.Product) error {
+	if err := database.Conn(ctx, r.db).Save(product).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *repository) Delete(id uint) error {
-	if err := r.db.Where("id =?", id).Delete(&models.Product{}).Error; err != nil {
+func (r *repository) Delete(ctx context.Context, id uint) error {
+	if err := database.Conn(ctx, r.db).Where("id =?", id).Delete(&models.Product{}).Error; err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (r *repository) FindByProductName(name string) (*models.Product, error) {
+func (r *repository) FindByProductName(ctx context.Context, name string) (*models.Product, error) {
 	var product models.Product
-	if err := r.db.Where("name =?", name).First(&product).Error; err != nil {
+	if err := database.Conn(ctx, r.db).Where("name =?", name).First(&product).Error; err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
-func (r *repository) FindByProductID(productID uint) (*models.Product, error) {
+func (r *repository) FindByProductID(ctx context.Context, productID uint) (*models.Product, error) {
 	product := &models.Product{}
-	err := r.db.
+	err := database.Conn(ctx, r.db).
 		Preload("Promotion").
 		Where("id = ? AND deleted_at IS NULL", productID).
 		First(product).Error
@@ -87,8 +107,8 @@ func (r *repository) FindByProductID(productID uint) (*models.Product, error) {
 	return product, nil
 }
 
-func (r *repository) DeleteCartItemByProductID(productID uint) error {
-	if err := r.db.Where("product_id =?", productID).Delete(&models.CartItem{}).Error; err != nil {
+func (r *repository) DeleteCartItemByProductID(ctx context.Context, productID uint) error {
+	if err := database.Conn(ctx, r.db).Where("product_id =?", productID).Delete(&models.CartItem{}).Error; err != nil {
 		return err
 	}
 	return nil
@@ -1,9 +1,11 @@
 package product
 
 type Request struct {
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" validate:"required"`
+	Name         string  `json:"name" validate:"required"`
+	Description  string  `json:"description"`
+	Price        float64 `json:"price" validate:"required,money"`
+	RestaurantID *uint   `json:"restaurant_id"`
+	PrepMinutes  uint    `json:"prep_minutes"`
 }
 
 type CreateRequest struct {
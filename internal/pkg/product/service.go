@@ -1,114 +1,180 @@
 package product
 
 import (
+	"context"
 	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/events"
+	"food-delivery-workshop/internal/core/tracing"
 	"food-delivery-workshop/internal/get"
 	"food-delivery-workshop/internal/models"
-	"github.com/gofiber/fiber/v2"
 	"github.com/jinzhu/copier"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type Service interface {
-	Create(c *fiber.Ctx, request *CreateRequest) (*models.Product, error)
-	Update(c *fiber.Ctx, request *UpdateRequest) (*models.Product, error)
-	GetProductByID(request *get.GetOne[uint]) (*models.Product, error)
-	GetAllProducts() ([]models.Product, error)
-	Delete(c *fiber.Ctx, request *get.GetOne[uint]) error
+	Create(ctx context.Context, request *CreateRequest) (*models.Product, error)
+	Update(ctx context.Context, request *UpdateRequest) (*models.Product, error)
+	GetProductByID(ctx context.Context, request *get.GetOne[uint]) (*models.Product, error)
+	GetAllProducts(ctx context.Context) ([]models.Product, error)
+	GetProductsByIDs(ctx context.Context, ids []uint) ([]models.Product, error)
+	GetProductsByRestaurantID(ctx context.Context, request *get.GetOne[uint]) ([]models.Product, error)
+	Delete(ctx context.Context, request *get.GetOne[uint]) error
 }
 
 type service struct {
-	repo Repository
+	repo   Repository
+	events events.Recorder
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, recorder events.Recorder) Service {
+	return &service{repo: repo, events: recorder}
 }
 
 // Create create a product
-func (s *service) Create(c *fiber.Ctx, request *CreateRequest) (*models.Product, error) {
+func (s *service) Create(ctx context.Context, request *CreateRequest) (*models.Product, error) {
+	ctx, span := tracing.Start(ctx, "product.Create")
+	defer span.End()
+
 	product := &models.Product{}
-	productName, err := s.repo.FindByProductName(request.Name)
+	productName, err := s.repo.FindByProductName(ctx, request.Name)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		logrus.Errorf("find product name error: %v", err)
+		logrus.WithContext(ctx).Errorf("find product name error: %v", err)
 		return nil, err
 	}
 
 	if productName != nil {
-		logrus.Errorf("product name already exist: %v", err)
-		return nil, errors.New("product name already exist")
+		logrus.WithContext(ctx).Errorf("product name already exist: %v", err)
+		return nil, apperror.Conflict("PRODUCT_NAME_EXISTS", "product name already exist")
 	}
 
 	_ = copier.Copy(product, request)
-	if err := s.repo.Create(product); err != nil {
-		logrus.Errorf("create product error: %v", err)
+	if err := s.repo.Create(ctx, product); err != nil {
+		logrus.WithContext(ctx).Errorf("create product error: %v", err)
 		return nil, err
 	}
 
 	return product, nil
 }
 
-func (s *service) Update(c *fiber.Ctx, request *UpdateRequest) (*models.Product, error) {
+func (s *service) Update(ctx context.Context, request *UpdateRequest) (*models.Product, error) {
+	ctx, span := tracing.Start(ctx, "product.Update")
+	defer span.End()
+
 	product := &models.Product{}
-	if err := s.repo.FindByID(request.ID, product); err != nil {
+	if err := s.repo.FindByID(ctx, request.ID, product); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("product not found")
+			return nil, apperror.NotFound("PRODUCT_NOT_FOUND", "product not found")
 		}
-		logrus.Errorf("find product by id error: %v", err)
+		logrus.WithContext(ctx).Errorf("find product by id error: %v", err)
 		return nil, err
 	}
 
-	productName, err := s.repo.FindByProductName(request.Name)
+	productName, err := s.repo.FindByProductName(ctx, request.Name)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		logrus.Errorf("find product name error: %v", err)
+		logrus.WithContext(ctx).Errorf("find product name error: %v", err)
 		return nil, err
 	}
 	if productName != nil {
-		logrus.Errorf("product name already exist: %v", err)
-		return nil, errors.New("product name already exist")
+		logrus.WithContext(ctx).Errorf("product name already exist: %v", err)
+		return nil, apperror.Conflict("PRODUCT_NAME_EXISTS", "product name already exist")
 	}
+	oldPrice := product.Price
 	_ = copier.Copy(product, request)
-	if err := s.repo.Update(product); err != nil {
-		logrus.Errorf("update product error: %v", err)
+	err = s.events.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, product); err != nil {
+			return err
+		}
+		if product.Price == oldPrice {
+			return s.events.Record(ctx, &events.ProductUpdated{Product: product})
+		}
+		return s.events.Record(ctx, &events.ProductUpdated{Product: product},
+			&events.ProductPriceChanged{ProductID: product.ID, OldPrice: oldPrice, NewPrice: product.Price})
+	})
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("update product error: %v", err)
 		return nil, err
 	}
-
 	return product, nil
 }
 
-func (s *service) GetProductByID(request *get.GetOne[uint]) (*models.Product, error) {
+func (s *service) GetProductByID(ctx context.Context, request *get.GetOne[uint]) (*models.Product, error) {
+	ctx, span := tracing.Start(ctx, "product.GetProductByID")
+	defer span.End()
+
 	product := &models.Product{}
-	if err := s.repo.FindByID(request.GetID(), product); err != nil {
+	if err := s.repo.FindByID(ctx, request.GetID(), product); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("PRODUCT_NOT_FOUND", "product not found")
+		}
+		logrus.WithContext(ctx).Errorf("find product by id error: %v", err)
 		return nil, err
 	}
 	
 	return product, nil
 }
 
-func (s *service) GetAllProducts() ([]models.Product, error) {
-	products, err := s.repo.FindAll()
+func (s *service) GetAllProducts(ctx context.Context) ([]models.Product, error) {
+	ctx, span := tracing.Start(ctx, "product.GetAllProducts")
+	defer span.End()
+
+	products, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// GetProductsByIDs returns the products found, in no particular order; missing
+// ids are skipped.
+func (s *service) GetProductsByIDs(ctx context.Context, ids []uint) ([]models.Product, error) {
+	ctx, span := tracing.Start(ctx, "product.GetProductsByIDs")
+	defer span.End()
+
+	products, err := s.repo.FindByIDs(ctx, ids)
 	if err != nil {
+		logrus.WithContext(ctx).Errorf("find products by ids error: %v", err)
 		return nil, err
 	}
+	return products, nil
+}
+
+// GetProductsByRestaurantID returns a restaurant's menu.
+func (s *service) GetProductsByRestaurantID(ctx context.Context, request *get.GetOne[uint]) ([]models.Product, error) {
+	ctx, span := tracing.Start(ctx, "product.GetProductsByRestaurantID")
+	defer span.End()
 
+	products, err := s.repo.FindByRestaurantID(ctx, request.GetID())
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find products by restaurant error: %v", err)
+		return nil, err
+	}
 	return products, nil
 }
 
-func (s *service) Delete(c *fiber.Ctx, request *get.GetOne[uint]) error {
+func (s *service) Delete(ctx context.Context, request *get.GetOne[uint]) error {
+	ctx, span := tracing.Start(ctx, "product.Delete")
+	defer span.End()
+
 	product := &models.Product{}
-	if err := s.repo.FindByID(request.GetID(), product); err != nil {
-		return errors.New("product not found")
+	if err := s.repo.FindByID(ctx, request.GetID(), product); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("PRODUCT_NOT_FOUND", "product not found")
+		}
+		logrus.WithContext(ctx).Errorf("find product by id error: %v", err)
+		return err
 	}
 
-	if err := s.repo.DeleteCartItemByProductID(product.ID); err != nil {
-		logrus.Errorf("delete cart item error: %v", err)
+	if err := s.repo.DeleteCartItemByProductID(ctx, product.ID); err != nil {
+		logrus.WithContext(ctx).Errorf("delete cart item error: %v", err)
 		return err
 	}
 	
-	err := s.repo.Delete(product.ID)
+	err := s.repo.Delete(ctx, product.ID)
 	if err != nil {
-		logrus.Errorf("delete product error: %v", err)
+		logrus.WithContext(ctx).Errorf("delete product error: %v", err)
 		return err
 	}
 
@@ -1,7 +1,9 @@
 package promotion
 
 import (
+	"food-delivery-workshop/internal/apperror"
 	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/validation"
 	"github.com/gofiber/fiber/v2"
 	"net/http"
 	"strconv"
@@ -15,36 +17,22 @@ import (
 // @Produce  json
 // @Param request body CreateRequest true "request body"
 // @Success 200 {object} models.Promotion
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
+// @Security ServiceKeyAuth
 // @Router /promotions [post]
 func Create(c *fiber.Ctx, service Service) error {
-	request := new(CreateRequest)
-	if err := c.BodyParser(request); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
-	}
-
-	if err := validatePromotionReq(request); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	request, err := validation.BindAndValidate[CreateRequest](c)
+	if err != nil {
+		return err
 	}
 
-	promotion, err := service.Create(c, request)
+	promotion, err := service.Create(c.UserContext(), request)
 	if err != nil {
-		if err.Error() == "promotion is already exist" {
-			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(http.StatusOK).JSON(promotion)
@@ -59,45 +47,30 @@ func Create(c *fiber.Ctx, service Service) error {
 // @Param id path uint true "Promotion ID"
 // @Param request body Request true "request data"
 // @Success 200 {object} models.Promotion
-// @Failure 401 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
+// @Security ServiceKeyAuth
 // @Router /promotions/{id} [put]
 func Update(c *fiber.Ctx, service Service) error {
 	promotionIDstr := c.Params("id")
 	promotionID, err := strconv.ParseUint(promotionIDstr, 10, 32)
 	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return apperror.Validation("INVALID_ID", "Invalid promotion ID")
 	}
 
-	request := new(UpdateRequest)
-	if err := c.BodyParser(request); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
-	}
-
-	if err := validatePromotionReq(request); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	request, err := validation.BindAndValidate[UpdateRequest](c)
+	if err != nil {
+		return err
 	}
 
-
 	request.ID = uint(promotionID)
-	updatedPromotion, err := service.Update(c, request)
+	updatedPromotion, err := service.Update(c.UserContext(), request)
 	if err != nil {
-		if err.Error() == "promotion not found" || err.Error() == "promotion is already exist" {
-			return c.Status(http.StatusNotFound).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(http.StatusOK).JSON(updatedPromotion)
@@ -110,33 +83,24 @@ func Update(c *fiber.Ctx, service Service) error {
 // @Produce json
 // @Param id path int true "Promotion ID"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
+// @Security ServiceKeyAuth
 // @Router /promotions/{id} [delete]
 func Delete(c *fiber.Ctx, service Service) error {
 	promotionIDstr := c.Params("id")
 	promotionID, err := strconv.ParseUint(promotionIDstr, 10, 32)
 	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid promotion ID",
-		})
+		return apperror.Validation("INVALID_ID", "Invalid promotion ID")
 	}
 
 	promotionIDUint := uint(promotionID)
 	ID := &get.GetOne[uint]{ID: promotionIDUint}
-	err = service.Delete(c, ID)
-	if err != nil {
-		if err.Error() == "Promotion not found" {
-			return c.Status(http.StatusNotFound).JSON(fiber.Map{
-				"error": err.Error(),
-			})
-		}
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	if err := service.Delete(c.UserContext(), ID); err != nil {
+		return err
 	}
 	return c.Status(http.StatusOK).JSON(fiber.Map{"message": "Promotion deleted successfully"})
 }
@@ -147,17 +111,16 @@ func Delete(c *fiber.Ctx, service Service) error {
 // @Accept json
 // @Produce json
 // @Success 200 {array} models.Promotion
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
+// @Security ServiceKeyAuth
 // @Router /promotions [get]
 func GetAllPromotion(c *fiber.Ctx, service Service) error {
-	promotions, err := service.GetAll()
+	promotions, err := service.GetAll(c.UserContext())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error getting Promotions",
-		})
+		return err
 	}
 	return c.Status(fiber.StatusOK).JSON(promotions)
 }
@@ -169,34 +132,26 @@ func GetAllPromotion(c *fiber.Ctx, service Service) error {
 // @Produce json
 // @Param id path int true "promotion ID"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
+// @Security ServiceKeyAuth
 // @Router /promotions/{id} [get]
 func GetPromotionByID(c *fiber.Ctx, service Service) error {
 	promotionIDstr := c.Params("id")
 	promotionID, err := strconv.ParseUint(promotionIDstr, 10, 32)
 	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid promotion ID",
-		})
+		return apperror.Validation("INVALID_ID", "Invalid promotion ID")
 	}
 
 	request := &get.GetOne[uint]{
 		ID: uint(promotionID),
 	}
-	promotion, err := service.GetByID(request)
+	promotion, err := service.GetByID(c.UserContext(), request)
 	if err != nil {
-		if err.Error() == "Promotion not found" {
-			return c.Status(http.StatusNotFound).JSON(fiber.Map{
-				"error": "Promotion not found",
-			})
-		}
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error getting promotion",
-		})
+		return err
 	}
 	return c.Status(http.StatusOK).JSON(promotion)
 }
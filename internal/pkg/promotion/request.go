@@ -2,7 +2,7 @@ package promotion
 
 type Request struct {
 	Code      string  `json:"code"  validate:"required"`
-	Discount  float64 `json:"discount"  validate:"required"`
+	Discount  float64 `json:"discount"  validate:"required,money"`
 	ProductID uint    `json:"product_id"`
 }
 
@@ -1,9 +1,11 @@
 package promotion
 
 import (
+	"context"
 	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/tracing"
 	"food-delivery-workshop/internal/models"
-	"github.com/gofiber/fiber/v2"
 	"github.com/jinzhu/copier"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -11,11 +13,12 @@ import (
 )
 
 type Service interface {
-	Create(c *fiber.Ctx, request *CreateRequest) (*models.Promotion, error)
-	Update(c *fiber.Ctx, request *UpdateRequest) (*models.Promotion, error)
-	GetByID(request *get.GetOne[uint]) (*models.Promotion, error)
-	GetAll() ([]*models.Promotion, error)
-	Delete(c *fiber.Ctx, request *get.GetOne[uint]) error
+	Create(ctx context.Context, request *CreateRequest) (*models.Promotion, error)
+	Update(ctx context.Context, request *UpdateRequest) (*models.Promotion, error)
+	GetByID(ctx context.Context, request *get.GetOne[uint]) (*models.Promotion, error)
+	GetAll(ctx context.Context) ([]*models.Promotion, error)
+	GetByProductIDs(ctx context.Context, productIDs []uint) ([]*models.Promotion, error)
+	Delete(ctx context.Context, request *get.GetOne[uint]) error
 }
 
 type service struct {
@@ -26,116 +29,151 @@ func NewService(repo Repository) Service {
 	return &service{repo: repo}
 }
 
-func (s *service) Create(c *fiber.Ctx, request *CreateRequest) (*models.Promotion, error) {
-	promoCode, err := s.repo.FindPromotionByCode(request.Code)
+func (s *service) Create(ctx context.Context, request *CreateRequest) (*models.Promotion, error) {
+	ctx, span := tracing.Start(ctx, "promotion.Create")
+	defer span.End()
+
+	promoCode, err := s.repo.FindPromotionByCode(ctx, request.Code)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		logrus.Errorf("find promotion error: %v", err)
+		logrus.WithContext(ctx).Errorf("find promotion error: %v", err)
 		return nil, err
 	}
 
 	if promoCode != nil {
-        logrus.Errorf("promotion is already exist: %v", err)
-        return nil, errors.New("promotion is already exist")
+        logrus.WithContext(ctx).Errorf("promotion is already exist: %v", err)
+        return nil, apperror.Conflict("PROMOTION_EXISTS", "promotion is already exist")
     }
 
-	existingPromotion, err := s.repo.FindPromotionByProductID(request.ProductID)
+	existingPromotion, err := s.repo.FindPromotionByProductID(ctx, request.ProductID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		logrus.Errorf("find promotion error: %v", err)
+		logrus.WithContext(ctx).Errorf("find promotion error: %v", err)
 		return nil, err
 	}
 
 	if existingPromotion != nil {
-		logrus.Errorf("promotion is already exist: %v", err)
-        return nil, errors.New("promotion is already exist")
+		logrus.WithContext(ctx).Errorf("promotion is already exist: %v", err)
+        return nil, apperror.Conflict("PROMOTION_EXISTS", "promotion is already exist")
     }
 
 	promotion := &models.Promotion{}
 	_ = copier.Copy(promotion, request)
-	if err := s.repo.Create(promotion); err != nil {
-		logrus.Errorf("create promotion error: %v", err)
+	if err := s.repo.Create(ctx, promotion); err != nil {
+		logrus.WithContext(ctx).Errorf("create promotion error: %v", err)
 		return nil, err
 	}
 
-	s.repo.Preload(promotion)
+	s.repo.Preload(ctx, promotion)
 	return promotion, nil
 }
 
-func (s *service) Update(c *fiber.Ctx, request *UpdateRequest) (*models.Promotion, error) {
+func (s *service) Update(ctx context.Context, request *UpdateRequest) (*models.Promotion, error) {
+	ctx, span := tracing.Start(ctx, "promotion.Update")
+	defer span.End()
+
 	promotion := &models.Promotion{}
-	if err := s.repo.FindByID(request.ID, promotion); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		logrus.Errorf("find promotion error: %v", err)
-        return nil, err		
+	if err := s.repo.FindByID(ctx, request.ID, promotion); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("PROMOTION_NOT_FOUND", "promotion not found")
+		}
+		logrus.WithContext(ctx).Errorf("find promotion error: %v", err)
+		return nil, err
 	}
 
-	promoCode, err := s.repo.FindPromotionByCode(request.Code)
+	promoCode, err := s.repo.FindPromotionByCode(ctx, request.Code)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		logrus.Errorf("find promotion error: %v", err)
+		logrus.WithContext(ctx).Errorf("find promotion error: %v", err)
 		return nil, err
 	}
 
 	if promoCode != nil && promoCode.ID!= request.ID {
-        logrus.Errorf("promotion is already exist: %v", err)
-        return nil, errors.New("promotion is already exist")
+        logrus.WithContext(ctx).Errorf("promotion is already exist: %v", err)
+        return nil, apperror.Conflict("PROMOTION_EXISTS", "promotion is already exist")
     }
 
-	existingPromotion, err := s.repo.FindPromotionByProductID(request.ProductID)
+	existingPromotion, err := s.repo.FindPromotionByProductID(ctx, request.ProductID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-        logrus.Errorf("find promotion error: %v", err)
+        logrus.WithContext(ctx).Errorf("find promotion error: %v", err)
         return nil, err
     }
 	if existingPromotion != nil && existingPromotion.ID != request.ID {
-		logrus.Errorf("promotion is already exist: %v", request.ProductID)
-        return nil, errors.New("promotion is already exist")
+		logrus.WithContext(ctx).Errorf("promotion is already exist: %v", request.ProductID)
+        return nil, apperror.Conflict("PROMOTION_EXISTS", "promotion is already exist")
     }
 
 	_ = copier.Copy(promotion, request)
-    if err := s.repo.Update(promotion); err != nil {
-        logrus.Errorf("update promotion error: %v", err)
+    if err := s.repo.Update(ctx, promotion); err != nil {
+        logrus.WithContext(ctx).Errorf("update promotion error: %v", err)
         return nil, err
     }
 
-	s.repo.Preload(promotion)
+	s.repo.Preload(ctx, promotion)
 	return promotion, nil
 }
 
-func (s *service) GetByID(request *get.GetOne[uint]) (*models.Promotion, error) {
+func (s *service) GetByID(ctx context.Context, request *get.GetOne[uint]) (*models.Promotion, error) {
+	ctx, span := tracing.Start(ctx, "promotion.GetByID")
+	defer span.End()
+
 	promotion := &models.Promotion{}
-	if err := s.repo.FindByID(request.GetID(), promotion); err != nil {
+	if err := s.repo.FindByID(ctx, request.GetID(), promotion); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("promotion not found")
+			return nil, apperror.NotFound("PROMOTION_NOT_FOUND", "promotion not found")
 		}
-		logrus.Errorf("find promotion error: %v", err)
+		logrus.WithContext(ctx).Errorf("find promotion error: %v", err)
 		return nil, err
 	}
 
 	return promotion, nil
 }
 
-func (s *service) GetAll() ([]*models.Promotion, error) {
-	promotions, err := s.repo.FindAll()
+func (s *service) GetAll(ctx context.Context) ([]*models.Promotion, error) {
+	ctx, span := tracing.Start(ctx, "promotion.GetAll")
+	defer span.End()
+
+	promotions, err := s.repo.FindAll(ctx)
 	if err != nil {
-		logrus.Errorf("find all promotion error: %v", err)
+		logrus.WithContext(ctx).Errorf("find all promotion error: %v", err)
 		return nil, err
 	}
 
 	return promotions, nil
 }
 
-func (s *service) Delete(c *fiber.Ctx, request *get.GetOne[uint]) error {
+// GetByProductIDs returns the promotions of the given products, without
+// preloading the products themselves.
+func (s *service) GetByProductIDs(ctx context.Context, productIDs []uint) ([]*models.Promotion, error) {
+	ctx, span := tracing.Start(ctx, "promotion.GetByProductIDs")
+	defer span.End()
+
+	promotions, err := s.repo.FindByProductIDs(ctx, productIDs)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find promotions by product ids error: %v", err)
+		return nil, err
+	}
+	return promotions, nil
+}
+
+func (s *service) Delete(ctx context.Context, request *get.GetOne[uint]) error {
+	ctx, span := tracing.Start(ctx, "promotion.Delete")
+	defer span.End()
+
 	promotion := &models.Promotion{}
-	if err := s.repo.FindByID(request.ID, promotion); err != nil {
-		logrus.Errorf("find promotion error: %v", err)
+	if err := s.repo.FindByID(ctx, request.ID, promotion); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("PROMOTION_NOT_FOUND", "promotion not found")
+		}
+		logrus.WithContext(ctx).Errorf("find promotion error: %v", err)
 		return err
 	}
 
-	if err := s.repo.DeletePromotionID(promotion.ID); err != nil {
-		logrus.Errorf("delete promotion error: %v", err)
+	if err := s.repo.DeletePromotionID(ctx, promotion.ID); err != nil {
+		logrus.WithContext(ctx).Errorf("delete promotion error: %v", err)
 		return err
 	}
 	
-	err := s.repo.Delete(promotion.ID)
+	err := s.repo.Delete(ctx, promotion.ID)
 	if err != nil {
-		logrus.Errorf("delete promotion error: %v", err)
+		logrus.WithContext(ctx).Errorf("delete promotion error: %v", err)
 		return err
 	}
 	return nil
@@ -0,0 +1,359 @@
+package restaurant
+
+import (
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/validation"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Create Create restaurant
+// @Summary Create a restaurant
+// @Description Create a restaurant
+// @Tags restaurant
+// @Accept  json
+// @Produce  json
+// @Param request body CreateRequest true "Restaurant"
+// @Success 200 {object} models.Restaurant
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /restaurants [post]
+func Create(c *fiber.Ctx, service Service) error {
+	request, err := validation.BindAndValidate[CreateRequest](c)
+	if err != nil {
+		return err
+	}
+
+	restaurant, err := service.Create(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(restaurant)
+}
+
+// Update update restaurant
+// @Summary update a restaurant
+// @Description update a restaurant
+// @Tags restaurant
+// @Accept  json
+// @Produce  json
+// @Param id path uint true "Restaurant ID"
+// @Param request body UpdateRequest true "Restaurant data"
+// @Success 200 {object} models.Restaurant
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /restaurants/{id} [put]
+func Update(c *fiber.Ctx, service Service) error {
+	restaurantID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid restaurant ID")
+	}
+
+	request, err := validation.BindAndValidate[UpdateRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.ID = uint(restaurantID)
+	updatedRestaurant, err := service.Update(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(updatedRestaurant)
+}
+
+// @Summary Get all restaurants
+// @Description Get all restaurants
+// @Tags restaurant
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Restaurant
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /restaurants [get]
+func GetAllRestaurant(c *fiber.Ctx, service Service) error {
+	restaurants, err := service.GetAllRestaurants(c.UserContext())
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(restaurants)
+}
+
+// @Summary Get restaurant by id
+// @Description Get restaurant by id
+// @Tags restaurant
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Success 200 {object} models.Restaurant
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /restaurants/{id} [get]
+func GetRestaurantByID(c *fiber.Ctx, service Service) error {
+	restaurantID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid restaurant ID")
+	}
+
+	restaurant, err := service.GetRestaurantByID(c.UserContext(), &get.GetOne[uint]{ID: uint(restaurantID)})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(restaurant)
+}
+
+// GetSchedule Get opening hours
+// @Summary Get restaurant opening hours
+// @Description Get weekly opening hours, upcoming holidays and whether the restaurant is open now (Asia/Bangkok)
+// @Tags restaurant
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Success 200 {object} ScheduleResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /restaurants/{id}/hours [get]
+func GetSchedule(c *fiber.Ctx, service Service) error {
+	restaurantID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid restaurant ID")
+	}
+
+	schedule, err := service.GetSchedule(c.UserContext(), &get.GetOne[uint]{ID: uint(restaurantID)})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schedule)
+}
+
+// UpdateHours Replace opening hours
+// @Summary Replace weekly opening hours
+// @Description Replace the weekly schedule. A day may have several intervals; closes_at before opens_at means the interval ends after midnight.
+// @Tags restaurant
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param request body HoursRequest true "Opening hours"
+// @Success 200 {object} ScheduleResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /restaurants/{id}/hours [put]
+func UpdateHours(c *fiber.Ctx, service Service) error {
+	restaurantID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid restaurant ID")
+	}
+
+	request, err := validation.BindAndValidate[HoursRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.ID = uint(restaurantID)
+	schedule, err := service.UpdateHours(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schedule)
+}
+
+// CreateHoliday Add holiday
+// @Summary Add a holiday override
+// @Description Close the restaurant for a date, or open it with special hours when opens_at and closes_at are given
+// @Tags restaurant
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param request body HolidayRequest true "Holiday"
+// @Success 201 {object} models.Holiday
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /restaurants/{id}/holidays [post]
+func CreateHoliday(c *fiber.Ctx, service Service) error {
+	restaurantID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid restaurant ID")
+	}
+
+	request, err := validation.BindAndValidate[HolidayRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.ID = uint(restaurantID)
+	holiday, err := service.CreateHoliday(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(holiday)
+}
+
+// DeleteHoliday Remove holiday
+// @Summary Remove a holiday override
+// @Description Remove a holiday override
+// @Tags restaurant
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param holiday_id path int true "Holiday ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /restaurants/{id}/holidays/{holiday_id} [delete]
+func DeleteHoliday(c *fiber.Ctx, service Service) error {
+	restaurantID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid restaurant ID")
+	}
+	holidayID, err := strconv.ParseUint(c.Params("holiday_id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid holiday ID")
+	}
+
+	request := &DeleteHolidayRequest{ID: uint(restaurantID), HolidayID: uint(holidayID)}
+	if err := service.DeleteHoliday(c.UserContext(), request); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Holiday deleted successfully",
+	})
+}
+
+// Pause Pause orders
+// @Summary Pause orders
+// @Description Stop accepting orders for the given number of minutes (busy kitchen)
+// @Tags restaurant
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param request body PauseRequest true "Pause duration"
+// @Success 200 {object} models.Restaurant
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /restaurants/{id}/pause [post]
+func Pause(c *fiber.Ctx, service Service) error {
+	restaurantID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid restaurant ID")
+	}
+
+	request, err := validation.BindAndValidate[PauseRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.ID = uint(restaurantID)
+	restaurant, err := service.Pause(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(restaurant)
+}
+
+// Resume Resume orders
+// @Summary Resume orders
+// @Description Cancel a pause and accept orders again
+// @Tags restaurant
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Success 200 {object} models.Restaurant
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /restaurants/{id}/pause [delete]
+func Resume(c *fiber.Ctx, service Service) error {
+	restaurantID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid restaurant ID")
+	}
+
+	restaurant, err := service.Resume(c.UserContext(), &get.GetOne[uint]{ID: uint(restaurantID)})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(restaurant)
+}
+
+// GetSlots Get delivery slots
+// @Summary Get delivery time slots
+// @Description List bookable 30 minute delivery slots for a date (YYYY-MM-DD, Asia/Bangkok) with remaining capacity
+// @Tags restaurant
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Success 200 {array} Slot
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /restaurants/{id}/slots [get]
+func GetSlots(c *fiber.Ctx, service Service) error {
+	restaurantID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid restaurant ID")
+	}
+
+	request, err := validation.BindAndValidate[SlotsRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.ID = uint(restaurantID)
+	slots, err := service.GetSlots(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(slots)
+}
@@ -0,0 +1,141 @@
+package restaurant
+
+import (
+	"context"
+	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	Create(ctx context.Context, restaurant *models.Restaurant) error
+	Update(ctx context.Context, restaurant *models.Restaurant) error
+	FindByID(ctx context.Context, id uint, restaurant *models.Restaurant) error
+	FindAll(ctx context.Context) ([]models.Restaurant, error)
+	ReplaceOpeningHours(ctx context.Context, restaurantID uint, hours []models.OpeningHour) error
+	FindOpeningHours(ctx context.Context, restaurantID uint) ([]models.OpeningHour, error)
+	CreateHoliday(ctx context.Context, holiday *models.Holiday) error
+	FindHolidayByID(ctx context.Context, id uint, holiday *models.Holiday) error
+	FindHolidays(ctx context.Context, restaurantID uint, fromDate string) ([]models.Holiday, error)
+	DeleteHoliday(ctx context.Context, id uint) error
+	CountScheduledOrders(ctx context.Context, restaurantID uint, from time.Time, to time.Time) (int64, error)
+	LockForBooking(ctx context.Context, restaurantID uint) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, restaurant *models.Restaurant) error {
+	if err := database.Conn(ctx, r.db).Create(restaurant).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) Update(ctx context.Context, restaurant *models.Restaurant) error {
+	if err := database.Conn(ctx, r.db).Save(restaurant).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindByID(ctx context.Context, id uint, restaurant *models.Restaurant) error {
+	if err := database.Conn(ctx, r.db).Where("id = ?", id).First(restaurant).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindAll(ctx context.Context) ([]models.Restaurant, error) {
+	var restaurants []models.Restaurant
+	if err := database.Conn(ctx, r.db).Where("deleted_at IS NULL").Find(&restaurants).Error; err != nil {
+		return nil, err
+	}
+	return restaurants, nil
+}
+
+func (r *repository) ReplaceOpeningHours(ctx context.Context, restaurantID uint, hours []models.OpeningHour) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("restaurant_id = ?", restaurantID).Delete(&models.OpeningHour{}).Error; err != nil {
+			return err
+		}
+		if len(hours) == 0 {
+			return nil
+		}
+		return tx.Create(&hours).Error
+	})
+}
+
+func (r *repository) FindOpeningHours(ctx context.Context, restaurantID uint) ([]models.OpeningHour, error) {
+	var hours []models.OpeningHour
+	err := database.Conn(ctx, r.db).Where("restaurant_id = ?", restaurantID).
+		Order("weekday, opens_at").Find(&hours).Error
+	if err != nil {
+		return nil, err
+	}
+	return hours, nil
+}
+
+func (r *repository) CreateHoliday(ctx context.Context, holiday *models.Holiday) error {
+	if err := database.Conn(ctx, r.db).Create(holiday).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindHolidayByID(ctx context.Context, id uint, holiday *models.Holiday) error {
+	if err := database.Conn(ctx, r.db).Where("id = ?", id).First(holiday).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindHolidays(ctx context.Context, restaurantID uint, fromDate string) ([]models.Holiday, error) {
+	var holidays []models.Holiday
+	err := database.Conn(ctx, r.db).Where("restaurant_id = ? AND date >= ?", restaurantID, fromDate).
+		Order("date").Find(&holidays).Error
+	if err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+func (r *repository) DeleteHoliday(ctx context.Context, id uint) error {
+	if err := database.Conn(ctx, r.db).Where("id = ?", id).Delete(&models.Holiday{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// CountScheduledOrders counts non-cancelled orders booked for delivery in [from, to).
+func (r *repository) CountScheduledOrders(ctx context.Context, restaurantID uint, from time.Time, to time.Time) (int64, error) {
+	var count int64
+	err := database.Conn(ctx, r.db).Model(&models.Order{}).
+		Where("restaurant_id = ? AND scheduled_for >= ? AND scheduled_for < ? AND status <> ?",
+			restaurantID, from, to, models.OrderStatusCancelled).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// LockForBooking locks the restaurant row until the transaction in ctx ends,
+// so bookings for the same restaurant are counted and inserted one at a time.
+func (r *repository) LockForBooking(ctx context.Context, restaurantID uint) error {
+	var restaurant models.Restaurant
+	err := database.Conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Where("id = ?", restaurantID).Take(&restaurant).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package restaurant
+
+type Request struct {
+	Name         string  `json:"name" validate:"required"`
+	Address      string  `json:"address"`
+	Latitude     float64 `json:"latitude" validate:"required,latitude"`
+	Longitude    float64 `json:"longitude" validate:"required,longitude"`
+	PrepMinutes  uint    `json:"prep_minutes"`
+	SlotCapacity uint    `json:"slot_capacity"`
+}
+
+type CreateRequest struct {
+	Request
+}
+
+type UpdateRequest struct {
+	ID uint `json:"-" path:"id"`
+	Request
+}
+
+type OpeningHourRequest struct {
+	Weekday  int    `json:"weekday" validate:"min=0,max=6"`
+	OpensAt  string `json:"opens_at" validate:"required,datetime=15:04"`
+	ClosesAt string `json:"closes_at" validate:"required,datetime=15:04"`
+}
+
+type HoursRequest struct {
+	ID    uint                 `json:"-" path:"id"`
+	Hours []OpeningHourRequest `json:"hours" validate:"dive"`
+}
+
+type HolidayRequest struct {
+	ID       uint   `json:"-" path:"id"`
+	Date     string `json:"date" validate:"required,datetime=2006-01-02"`
+	Name     string `json:"name"`
+	OpensAt  string `json:"opens_at" validate:"required_with=ClosesAt,omitempty,datetime=15:04"`
+	ClosesAt string `json:"closes_at" validate:"required_with=OpensAt,omitempty,datetime=15:04"`
+}
+
+type DeleteHolidayRequest struct {
+	ID        uint `json:"-" path:"id"`
+	HolidayID uint `json:"-" path:"holiday_id"`
+}
+
+type SlotsRequest struct {
+	ID   uint   `json:"-" path:"id"`
+	Date string `query:"date" validate:"required,datetime=2006-01-02"`
+}
+
+type PauseRequest struct {
+	ID      uint `json:"-" path:"id"`
+	Minutes uint `json:"minutes" validate:"required,min=1,max=1440"`
+}
@@ -0,0 +1,15 @@
+package restaurant
+
+import (
+	"food-delivery-workshop/internal/models"
+	"time"
+)
+
+type ScheduleResponse struct {
+	Timezone    string               `json:"timezone"`
+	OpenNow     bool                 `json:"open_now"`
+	NextOpenAt  *time.Time           `json:"next_open_at"`
+	PausedUntil *time.Time           `json:"paused_until"`
+	Hours       []models.OpeningHour `json:"hours"`
+	Holidays    []models.Holiday     `json:"holidays"`
+}
@@ -0,0 +1,116 @@
+package restaurant
+
+import (
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/models"
+	"time"
+)
+
+// Location is the timezone used to interpret opening hours and holiday dates.
+var Location = loadLocation()
+
+const dateLayout = "2006-01-02"
+
+// ถ้าเครื่องไม่มี tzdata ให้ใช้ +07:00 แทน (ประเทศไทยไม่มี daylight saving)
+func loadLocation() *time.Location {
+	location, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		return time.FixedZone("ICT", 7*60*60)
+	}
+	return location
+}
+
+type Schedule struct {
+	Restaurant *models.Restaurant
+	Hours      []models.OpeningHour
+	Holidays   []models.Holiday
+}
+
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// NextOpenAt returns the earliest time at or after from when the restaurant
+// accepts orders, or nil if it does not open within the next two weeks.
+func (s *Schedule) NextOpenAt(from time.Time) *time.Time {
+	from = from.In(Location)
+	if s.Restaurant.PausedUntil != nil && s.Restaurant.PausedUntil.After(from) {
+		from = s.Restaurant.PausedUntil.In(Location)
+	}
+
+	var next *time.Time
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, Location)
+	// เริ่มจากเมื่อวานเพื่อรวมช่วงที่เปิดข้ามเที่ยงคืน
+	for offset := -1; offset <= 14; offset++ {
+		for _, iv := range s.intervals(day.AddDate(0, 0, offset)) {
+			if !iv.end.After(from) {
+				continue
+			}
+			candidate := iv.start
+			if candidate.Before(from) {
+				candidate = from
+			}
+			if next == nil || candidate.Before(*next) {
+				next = &candidate
+			}
+		}
+		if next != nil {
+			break
+		}
+	}
+	return next
+}
+
+func (s *Schedule) IsOpenAt(at time.Time) bool {
+	next := s.NextOpenAt(at)
+	return next != nil && next.Equal(at.In(Location))
+}
+
+func (s *Schedule) intervals(day time.Time) []interval {
+	date := day.Format(dateLayout)
+	for _, holiday := range s.Holidays {
+		if holiday.Date != date {
+			continue
+		}
+		if holiday.OpensAt == "" || holiday.ClosesAt == "" {
+			return nil
+		}
+		return []interval{newInterval(day, holiday.OpensAt, holiday.ClosesAt)}
+	}
+
+	// ร้านที่ยังไม่ได้ตั้งเวลาเปิดปิด ถือว่าเปิดตลอด 24 ชั่วโมง
+	if len(s.Hours) == 0 {
+		return []interval{{start: day, end: day.AddDate(0, 0, 1)}}
+	}
+
+	intervals := []interval{}
+	for _, hour := range s.Hours {
+		if hour.Weekday == int(day.Weekday()) {
+			intervals = append(intervals, newInterval(day, hour.OpensAt, hour.ClosesAt))
+		}
+	}
+	return intervals
+}
+
+func newInterval(day time.Time, opensAt, closesAt string) interval {
+	start := atClock(day, opensAt)
+	end := atClock(day, closesAt)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return interval{start: start, end: end}
+}
+
+func atClock(day time.Time, clock string) time.Time {
+	t, _ := time.Parse("15:04", clock)
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, Location)
+}
+
+// closedError is returned when an order is placed while the restaurant is closed or paused.
+func closedError(restaurantID uint, nextOpenAt *time.Time) error {
+	return apperror.Conflict("RESTAURANT_CLOSED", "restaurant is closed").WithDetails(map[string]interface{}{
+		"restaurant_id": restaurantID,
+		"next_open_at":  nextOpenAt,
+	})
+}
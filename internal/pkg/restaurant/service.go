@@ -0,0 +1,364 @@
+package restaurant
+
+import (
+	"context"
+	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/tracing"
+	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/models"
+	"time"
+
+	"github.com/jinzhu/copier"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	Create(ctx context.Context, request *CreateRequest) (*models.Restaurant, error)
+	Update(ctx context.Context, request *UpdateRequest) (*models.Restaurant, error)
+	GetRestaurantByID(ctx context.Context, request *get.GetOne[uint]) (*models.Restaurant, error)
+	GetAllRestaurants(ctx context.Context) ([]models.Restaurant, error)
+	GetSchedule(ctx context.Context, request *get.GetOne[uint]) (*ScheduleResponse, error)
+	UpdateHours(ctx context.Context, request *HoursRequest) (*ScheduleResponse, error)
+	CreateHoliday(ctx context.Context, request *HolidayRequest) (*models.Holiday, error)
+	DeleteHoliday(ctx context.Context, request *DeleteHolidayRequest) error
+	Pause(ctx context.Context, request *PauseRequest) (*models.Restaurant, error)
+	Resume(ctx context.Context, request *get.GetOne[uint]) (*models.Restaurant, error)
+	CheckOpen(ctx context.Context, restaurantID uint, at time.Time) error
+	GetSlots(ctx context.Context, request *SlotsRequest) ([]Slot, error)
+	CheckSlot(ctx context.Context, restaurantID uint, scheduledFor time.Time) error
+	ReserveSlot(ctx context.Context, restaurantID uint, scheduledFor time.Time) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Create(ctx context.Context, request *CreateRequest) (*models.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "restaurant.Create")
+	defer span.End()
+
+	restaurant := &models.Restaurant{}
+	_ = copier.Copy(restaurant, request)
+	if err := s.repo.Create(ctx, restaurant); err != nil {
+		logrus.WithContext(ctx).Errorf("create restaurant error: %v", err)
+		return nil, err
+	}
+
+	return restaurant, nil
+}
+
+func (s *service) Update(ctx context.Context, request *UpdateRequest) (*models.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "restaurant.Update")
+	defer span.End()
+
+	restaurant := &models.Restaurant{}
+	if err := s.repo.FindByID(ctx, request.ID, restaurant); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("RESTAURANT_NOT_FOUND", "restaurant not found")
+		}
+		logrus.WithContext(ctx).Errorf("find restaurant by id error: %v", err)
+		return nil, err
+	}
+
+	_ = copier.Copy(restaurant, request)
+	if err := s.repo.Update(ctx, restaurant); err != nil {
+		logrus.WithContext(ctx).Errorf("update restaurant error: %v", err)
+		return nil, err
+	}
+
+	return restaurant, nil
+}
+
+func (s *service) GetRestaurantByID(ctx context.Context, request *get.GetOne[uint]) (*models.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "restaurant.GetRestaurantByID")
+	defer span.End()
+
+	restaurant := &models.Restaurant{}
+	if err := s.repo.FindByID(ctx, request.GetID(), restaurant); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("RESTAURANT_NOT_FOUND", "restaurant not found")
+		}
+		logrus.WithContext(ctx).Errorf("find restaurant by id error: %v", err)
+		return nil, err
+	}
+
+	return restaurant, nil
+}
+
+func (s *service) GetAllRestaurants(ctx context.Context) ([]models.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "restaurant.GetAllRestaurants")
+	defer span.End()
+
+	restaurants, err := s.repo.FindAll(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find all restaurant error: %v", err)
+		return nil, err
+	}
+
+	return restaurants, nil
+}
+
+func (s *service) GetSchedule(ctx context.Context, request *get.GetOne[uint]) (*ScheduleResponse, error) {
+	ctx, span := tracing.Start(ctx, "restaurant.GetSchedule")
+	defer span.End()
+
+	schedule, err := s.loadSchedule(ctx, request.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &ScheduleResponse{
+		Timezone:    Location.String(),
+		OpenNow:     schedule.IsOpenAt(now),
+		NextOpenAt:  schedule.NextOpenAt(now),
+		PausedUntil: schedule.Restaurant.PausedUntil,
+		Hours:       schedule.Hours,
+		Holidays:    schedule.Holidays,
+	}, nil
+}
+
+func (s *service) UpdateHours(ctx context.Context, request *HoursRequest) (*ScheduleResponse, error) {
+	ctx, span := tracing.Start(ctx, "restaurant.UpdateHours")
+	defer span.End()
+
+	if _, err := s.GetRestaurantByID(ctx, &get.GetOne[uint]{ID: request.ID}); err != nil {
+		return nil, err
+	}
+
+	hours := []models.OpeningHour{}
+	for _, req := range request.Hours {
+		hours = append(hours, models.OpeningHour{
+			RestaurantID: request.ID,
+			Weekday:      req.Weekday,
+			OpensAt:      req.OpensAt,
+			ClosesAt:     req.ClosesAt,
+		})
+	}
+	if err := s.repo.ReplaceOpeningHours(ctx, request.ID, hours); err != nil {
+		logrus.WithContext(ctx).Errorf("replace opening hours error: %v", err)
+		return nil, err
+	}
+
+	return s.GetSchedule(ctx, &get.GetOne[uint]{ID: request.ID})
+}
+
+func (s *service) CreateHoliday(ctx context.Context, request *HolidayRequest) (*models.Holiday, error) {
+	ctx, span := tracing.Start(ctx, "restaurant.CreateHoliday")
+	defer span.End()
+
+	if _, err := s.GetRestaurantByID(ctx, &get.GetOne[uint]{ID: request.ID}); err != nil {
+		return nil, err
+	}
+
+	holiday := &models.Holiday{
+		RestaurantID: request.ID,
+		Date:         request.Date,
+		Name:         request.Name,
+		OpensAt:      request.OpensAt,
+		ClosesAt:     request.ClosesAt,
+	}
+	if err := s.repo.CreateHoliday(ctx, holiday); err != nil {
+		logrus.WithContext(ctx).Errorf("create holiday error: %v", err)
+		return nil, err
+	}
+
+	return holiday, nil
+}
+
+func (s *service) DeleteHoliday(ctx context.Context, request *DeleteHolidayRequest) error {
+	ctx, span := tracing.Start(ctx, "restaurant.DeleteHoliday")
+	defer span.End()
+
+	holiday := &models.Holiday{}
+	if err := s.repo.FindHolidayByID(ctx, request.HolidayID, holiday); err != nil || holiday.RestaurantID != request.ID {
+		return apperror.NotFound("HOLIDAY_NOT_FOUND", "holiday not found")
+	}
+
+	if err := s.repo.DeleteHoliday(ctx, holiday.ID); err != nil {
+		logrus.WithContext(ctx).Errorf("delete holiday error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (s *service) Pause(ctx context.Context, request *PauseRequest) (*models.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "restaurant.Pause")
+	defer span.End()
+
+	restaurant, err := s.GetRestaurantByID(ctx, &get.GetOne[uint]{ID: request.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	pausedUntil := time.Now().Add(time.Duration(request.Minutes) * time.Minute)
+	restaurant.PausedUntil = &pausedUntil
+	if err := s.repo.Update(ctx, restaurant); err != nil {
+		logrus.WithContext(ctx).Errorf("pause restaurant error: %v", err)
+		return nil, err
+	}
+
+	return restaurant, nil
+}
+
+func (s *service) Resume(ctx context.Context, request *get.GetOne[uint]) (*models.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "restaurant.Resume")
+	defer span.End()
+
+	restaurant, err := s.GetRestaurantByID(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	restaurant.PausedUntil = nil
+	if err := s.repo.Update(ctx, restaurant); err != nil {
+		logrus.WithContext(ctx).Errorf("resume restaurant error: %v", err)
+		return nil, err
+	}
+
+	return restaurant, nil
+}
+
+// CheckOpen returns a RESTAURANT_CLOSED error if the restaurant does not accept orders at the given time.
+func (s *service) CheckOpen(ctx context.Context, restaurantID uint, at time.Time) error {
+	ctx, span := tracing.Start(ctx, "restaurant.CheckOpen")
+	defer span.End()
+
+	schedule, err := s.loadSchedule(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	if !schedule.IsOpenAt(at) {
+		return closedError(restaurantID, schedule.NextOpenAt(at))
+	}
+	return nil
+}
+
+func (s *service) GetSlots(ctx context.Context, request *SlotsRequest) ([]Slot, error) {
+	ctx, span := tracing.Start(ctx, "restaurant.GetSlots")
+	defer span.End()
+
+	day, err := time.ParseInLocation(dateLayout, request.Date, Location)
+	if err != nil {
+		return nil, apperror.Validation("INVALID_DATE", "invalid date")
+	}
+
+	schedule, err := s.loadSchedule(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	earliest := time.Now().Add(LeadTime)
+	latest := time.Now().Add(MaxAdvance)
+	slots := []Slot{}
+	for start := day; start.Before(day.AddDate(0, 0, 1)); start = start.Add(SlotMinutes * time.Minute) {
+		if start.Before(earliest) || start.After(latest) || !schedule.slotOpen(start) {
+			continue
+		}
+
+		slot, err := s.newSlot(ctx, schedule, start)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, *slot)
+	}
+
+	return slots, nil
+}
+
+// CheckSlot returns an INVALID_DELIVERY_SLOT or RESTAURANT_CLOSED error if an order cannot be scheduled for delivery at the given time.
+func (s *service) CheckSlot(ctx context.Context, restaurantID uint, scheduledFor time.Time) error {
+	ctx, span := tracing.Start(ctx, "restaurant.CheckSlot")
+	defer span.End()
+
+	local := scheduledFor.In(Location)
+	if local.Second() != 0 || local.Nanosecond() != 0 || local.Minute()%SlotMinutes != 0 {
+		return slotError("delivery time must start on a 30 minute slot")
+	}
+	if scheduledFor.Before(time.Now().Add(LeadTime)) {
+		return slotError("delivery time must be at least 45 minutes from now")
+	}
+	if scheduledFor.After(time.Now().Add(MaxAdvance)) {
+		return slotError("delivery time must be within 7 days")
+	}
+
+	schedule, err := s.loadSchedule(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	if !schedule.slotOpen(scheduledFor) {
+		return closedError(restaurantID, schedule.NextOpenAt(ReleaseAt(scheduledFor)))
+	}
+
+	slot, err := s.newSlot(ctx, schedule, local)
+	if err != nil {
+		return err
+	}
+	if slot.Remaining <= 0 {
+		return slotError("delivery slot is full")
+	}
+	return nil
+}
+
+// ReserveSlot is CheckSlot for the transaction that creates the order. It locks
+// the restaurant first, so concurrent checkouts cannot both take the last place
+// in a slot; the lock is held until the transaction commits.
+func (s *service) ReserveSlot(ctx context.Context, restaurantID uint, scheduledFor time.Time) error {
+	ctx, span := tracing.Start(ctx, "restaurant.ReserveSlot")
+	defer span.End()
+
+	if err := s.repo.LockForBooking(ctx, restaurantID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("RESTAURANT_NOT_FOUND", "restaurant not found")
+		}
+		logrus.WithContext(ctx).Errorf("lock restaurant error: %v", err)
+		return err
+	}
+	return s.CheckSlot(ctx, restaurantID, scheduledFor)
+}
+
+func (s *service) newSlot(ctx context.Context, schedule *Schedule, start time.Time) (*Slot, error) {
+	end := start.Add(SlotMinutes * time.Minute)
+	booked, err := s.repo.CountScheduledOrders(ctx, schedule.Restaurant.ID, start, end)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("count scheduled orders error: %v", err)
+		return nil, err
+	}
+
+	capacity := slotCapacity(schedule)
+	remaining := capacity - int(booked)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &Slot{StartsAt: start, EndsAt: end, Capacity: capacity, Remaining: remaining}, nil
+}
+
+func (s *service) loadSchedule(ctx context.Context, restaurantID uint) (*Schedule, error) {
+	restaurant, err := s.GetRestaurantByID(ctx, &get.GetOne[uint]{ID: restaurantID})
+	if err != nil {
+		return nil, err
+	}
+
+	hours, err := s.repo.FindOpeningHours(ctx, restaurantID)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find opening hours error: %v", err)
+		return nil, err
+	}
+
+	yesterday := time.Now().In(Location).AddDate(0, 0, -1).Format(dateLayout)
+	holidays, err := s.repo.FindHolidays(ctx, restaurantID, yesterday)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find holidays error: %v", err)
+		return nil, err
+	}
+
+	return &Schedule{Restaurant: restaurant, Hours: hours, Holidays: holidays}, nil
+}
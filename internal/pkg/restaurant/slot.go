@@ -0,0 +1,43 @@
+package restaurant
+
+import (
+	"food-delivery-workshop/internal/apperror"
+	"time"
+)
+
+const (
+	SlotMinutes         = 30
+	LeadTime            = 45 * time.Minute // ส่งเข้าครัวก่อนเวลาส่งเท่านี้ และต้องสั่งล่วงหน้าอย่างน้อยเท่านี้
+	MaxAdvance          = 7 * 24 * time.Hour
+	DefaultSlotCapacity = 10
+)
+
+type Slot struct {
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Capacity  int       `json:"capacity"`
+	Remaining int       `json:"remaining"`
+}
+
+// slotError is returned when a requested delivery time cannot be booked.
+func slotError(reason string) error {
+	return apperror.Validation("INVALID_DELIVERY_SLOT", reason)
+}
+
+// ReleaseAt is when an order scheduled for the given delivery time is sent to the kitchen.
+func ReleaseAt(scheduledFor time.Time) time.Time {
+	return scheduledFor.Add(-LeadTime)
+}
+
+// slotOpen reports whether the kitchen is open both when the order is
+// released and at the delivery time itself.
+func (s *Schedule) slotOpen(startsAt time.Time) bool {
+	return s.IsOpenAt(ReleaseAt(startsAt)) && s.IsOpenAt(startsAt)
+}
+
+func slotCapacity(s *Schedule) int {
+	if s.Restaurant.SlotCapacity == 0 {
+		return DefaultSlotCapacity
+	}
+	return int(s.Restaurant.SlotCapacity)
+}
@@ -0,0 +1,142 @@
+package rider
+
+import (
+	"context"
+	"errors"
+	"food-delivery-workshop/internal/core/tracing"
+	"food-delivery-workshop/internal/geo"
+	"food-delivery-workshop/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// reofferCooldown is how long a rider who declined an order, or let the offer
+// expire, is skipped for that order. After it the order can go back to them,
+// so an order is not stuck once every nearby rider has said no once.
+const reofferCooldown = 2 * time.Minute
+
+// StartDispatcher runs DispatchOrders every interval in the background until ctx is
+// cancelled. Each run gets at most one interval to finish.
+func StartDispatcher(ctx context.Context, service Service, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				runCtx, cancel := context.WithTimeout(ctx, interval)
+				if err := service.DispatchOrders(runCtx); err != nil {
+					logrus.WithContext(ctx).Errorf("dispatch orders error: %v", err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// DispatchOrders expires offers that timed out and offers every ready,
+// unassigned order to the nearest available rider who has not turned it down
+// within reofferCooldown.
+func (s *service) DispatchOrders(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "rider.DispatchOrders")
+	defer span.End()
+
+	s.dispatchMu.Lock()
+	defer s.dispatchMu.Unlock()
+
+	expired, err := s.repo.FindExpiredAssignments(ctx, time.Now())
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find expired assignments error: %v", err)
+		return err
+	}
+	for _, assignment := range expired {
+		// OFFER_CLOSED แปลว่าไรเดอร์ตอบทันก่อนหมดเวลา
+		if err := s.respond(ctx, assignment, models.AssignmentStatusExpired); err != nil && !errors.Is(err, errOfferClosed()) {
+			return err
+		}
+	}
+
+	orders, err := s.orderRepo.FindReadyUnassigned(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find ready orders error: %v", err)
+		return err
+	}
+
+	for _, order := range orders {
+		if err := s.offerToNearestRider(ctx, order); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *service) offerToNearestRider(ctx context.Context, order *models.Order) error {
+	history, err := s.repo.FindAssignmentsByOrderID(ctx, order.ID)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find assignments by order error: %v", err)
+		return err
+	}
+
+	skipped := map[uint]bool{}
+	for _, assignment := range history {
+		if assignment.Status == models.AssignmentStatusOffered {
+			return nil // ยังรอไรเดอร์ตอบรับอยู่
+		}
+		if assignment.RespondedAt != nil && time.Since(*assignment.RespondedAt) < reofferCooldown {
+			skipped[assignment.RiderID] = true
+		}
+	}
+
+	riders, err := s.repo.FindAvailableRiders(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find available riders error: %v", err)
+		return err
+	}
+
+	var nearest *models.Rider
+	var nearestDistance float64
+	for _, rider := range riders {
+		if skipped[rider.ID] {
+			continue
+		}
+		distance := geo.DistanceKm(rider.Latitude, rider.Longitude, order.Restaurant.Latitude, order.Restaurant.Longitude)
+		if nearest == nil || distance < nearestDistance {
+			nearest = rider
+			nearestDistance = distance
+		}
+	}
+
+	if nearest == nil {
+		logrus.WithContext(ctx).WithFields(logrus.Fields{
+			"order_id":     order.ID,
+			"riders":       len(riders),
+			"cooling_down": len(skipped),
+		}).Warn("no available rider for order")
+		return nil
+	}
+
+	assignment := &models.RiderAssignment{
+		OrderID:   order.ID,
+		RiderID:   nearest.ID,
+		Status:    models.AssignmentStatusOffered,
+		Distance:  nearestDistance,
+		ExpiresAt: time.Now().Add(s.offerTimeout),
+	}
+	if err := s.repo.CreateAssignment(ctx, assignment); err != nil {
+		logrus.WithContext(ctx).Errorf("create assignment error: %v", err)
+		return err
+	}
+
+	logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"assignment_id": assignment.ID,
+		"order_id":      order.ID,
+		"rider_id":      nearest.ID,
+		"distance_km":   nearestDistance,
+		"candidates":    len(riders),
+	}).Info("order offered to rider")
+	return nil
+}
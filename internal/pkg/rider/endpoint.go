@@ -0,0 +1,278 @@
+package rider
+
+import (
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/validation"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Register Register as rider
+// @Summary Register a rider profile
+// @Description Create a rider profile for the current user
+// @Tags rider
+// @Accept  json
+// @Produce  json
+// @Param request body RegisterRequest true "Rider profile"
+// @Success 201 {object} models.Rider
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /rider [post]
+func Register(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := validation.BindAndValidate[RegisterRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.UserID = principal.UserID
+	rider, err := service.Register(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rider)
+}
+
+// GetRider Get rider profile
+// @Summary Get rider profile
+// @Description Get the current user's rider profile
+// @Tags rider
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} models.Rider
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /rider [get]
+func GetRider(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	rider, err := service.GetRider(c.UserContext(), &GetRequest{UserID: principal.UserID})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(rider)
+}
+
+// UpdateStatus Update rider status
+// @Summary Go online or offline
+// @Description Set the rider status to online or offline
+// @Tags rider
+// @Accept  json
+// @Produce  json
+// @Param request body StatusRequest true "Status"
+// @Success 200 {object} models.Rider
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /rider/status [put]
+func UpdateStatus(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := validation.BindAndValidate[StatusRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.UserID = principal.UserID
+	rider, err := service.UpdateStatus(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(rider)
+}
+
+// UpdateLocation Update rider location
+// @Summary Update current location
+// @Description Report the rider's current location
+// @Tags rider
+// @Accept  json
+// @Produce  json
+// @Param request body LocationRequest true "Location"
+// @Success 200 {object} models.Rider
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /rider/location [post]
+func UpdateLocation(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := validation.BindAndValidate[LocationRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.UserID = principal.UserID
+	rider, err := service.UpdateLocation(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(rider)
+}
+
+// GetOffers Get open offers
+// @Summary Get open order offers
+// @Description Get orders currently offered to the rider
+// @Tags rider
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} models.RiderAssignment
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /rider/offers [get]
+func GetOffers(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	offers, err := service.GetOffers(c.UserContext(), &GetRequest{UserID: principal.UserID})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(offers)
+}
+
+// AcceptOffer Accept an offer
+// @Summary Accept an order offer
+// @Description Accept an order offered to the rider
+// @Tags rider
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Offer ID"
+// @Success 200 {object} models.RiderAssignment
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /rider/offers/{id}/accept [post]
+func AcceptOffer(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	assignmentID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid offer ID")
+	}
+
+	request := &OfferRequest{UserID: principal.UserID, AssignmentID: uint(assignmentID)}
+
+	assignment, err := service.AcceptOffer(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(assignment)
+}
+
+// DeclineOffer Decline an offer
+// @Summary Decline an order offer
+// @Description Decline an order offered to the rider so it is re-offered to the next rider
+// @Tags rider
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Offer ID"
+// @Success 200 {object} models.RiderAssignment
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /rider/offers/{id}/decline [post]
+func DeclineOffer(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	assignmentID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid offer ID")
+	}
+
+	request := &OfferRequest{UserID: principal.UserID, AssignmentID: uint(assignmentID)}
+
+	assignment, err := service.DeclineOffer(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(assignment)
+}
+
+// UpdateOrderStatus Update a delivery
+// @Summary Mark an assigned order picked up or delivered
+// @Description Move an order assigned to the current rider to picked_up or delivered
+// @Tags rider
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Param request body OrderStatusRequest true "Status request"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /rider/orders/{id}/status [put]
+func UpdateOrderStatus(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	orderID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid order ID")
+	}
+
+	request, err := validation.BindAndValidate[OrderStatusRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.UserID = principal.UserID
+	request.OrderID = uint(orderID)
+	order, err := service.UpdateOrderStatus(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(order)
+}
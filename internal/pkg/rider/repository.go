@@ -0,0 +1,148 @@
+package rider
+
+import (
+	"context"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(ctx context.Context, rider *models.Rider) error
+	Update(ctx context.Context, rider *models.Rider) error
+	FindByUserID(ctx context.Context, userID uint) (*models.Rider, error)
+	FindAvailableRiders(ctx context.Context) ([]*models.Rider, error)
+	CreateAssignment(ctx context.Context, assignment *models.RiderAssignment) error
+	UpdateAssignment(ctx context.Context, assignment *models.RiderAssignment) error
+	CloseAssignment(ctx context.Context, assignment *models.RiderAssignment, status string, now time.Time) error
+	FindAssignmentByID(ctx context.Context, id uint) (*models.RiderAssignment, error)
+	FindPendingAssignmentsByRiderID(ctx context.Context, riderID uint) ([]*models.RiderAssignment, error)
+	FindAssignmentsByOrderID(ctx context.Context, orderID uint) ([]*models.RiderAssignment, error)
+	FindExpiredAssignments(ctx context.Context, now time.Time) ([]*models.RiderAssignment, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, rider *models.Rider) error {
+	if err := database.Conn(ctx, r.db).Create(rider).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) Update(ctx context.Context, rider *models.Rider) error {
+	if err := database.Conn(ctx, r.db).Save(rider).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindByUserID(ctx context.Context, userID uint) (*models.Rider, error) {
+	rider := &models.Rider{}
+	if err := database.Conn(ctx, r.db).Where("user_id = ?", userID).First(rider).Error; err != nil {
+		return nil, err
+	}
+	return rider, nil
+}
+
+// FindAvailableRiders returns online riders with a known location who are not
+// delivering an order and are not holding an open offer.
+func (r *repository) FindAvailableRiders(ctx context.Context) ([]*models.Rider, error) {
+	var riders []*models.Rider
+	err := database.Conn(ctx, r.db).Where("status = ? AND location_updated_at IS NOT NULL", models.RiderStatusOnline).
+		Where("NOT EXISTS (SELECT 1 FROM orders WHERE orders.rider_id = riders.id AND orders.status IN ? AND orders.deleted_at IS NULL)",
+			[]string{models.OrderStatusReady, models.OrderStatusPickedUp}).
+		Where("NOT EXISTS (SELECT 1 FROM rider_assignments WHERE rider_assignments.rider_id = riders.id AND rider_assignments.status = ? AND rider_assignments.deleted_at IS NULL)",
+			models.AssignmentStatusOffered).
+		Find(&riders).Error
+	if err != nil {
+		return nil, err
+	}
+	return riders, nil
+}
+
+func (r *repository) CreateAssignment(ctx context.Context, assignment *models.RiderAssignment) error {
+	if err := database.Conn(ctx, r.db).Create(assignment).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) UpdateAssignment(ctx context.Context, assignment *models.RiderAssignment) error {
+	if err := database.Conn(ctx, r.db).Omit("Order").Save(assignment).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// CloseAssignment moves an offer that is still open to status. Accepting and
+// declining also need the offer not to have expired, so a rider cannot answer
+// an offer the dispatcher is expiring and handing to someone else.
+func (r *repository) CloseAssignment(ctx context.Context, assignment *models.RiderAssignment, status string, now time.Time) error {
+	query := database.Conn(ctx, r.db).Model(&models.RiderAssignment{}).
+		Where("id = ? AND status = ?", assignment.ID, models.AssignmentStatusOffered)
+	if status != models.AssignmentStatusExpired {
+		query = query.Where("expires_at > ?", now)
+	}
+	result := query.Updates(map[string]interface{}{"status": status, "responded_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errOfferClosed()
+	}
+	assignment.Status = status
+	assignment.RespondedAt = &now
+	return nil
+}
+
+func errOfferClosed() error {
+	return apperror.Conflict("OFFER_CLOSED", "offer is no longer open")
+}
+
+func (r *repository) FindAssignmentByID(ctx context.Context, id uint) (*models.RiderAssignment, error) {
+	assignment := &models.RiderAssignment{}
+	err := database.Conn(ctx, r.db).Preload("Order.Restaurant").Where("id = ?", id).First(assignment).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+func (r *repository) FindPendingAssignmentsByRiderID(ctx context.Context, riderID uint) ([]*models.RiderAssignment, error) {
+	var assignments []*models.RiderAssignment
+	err := database.Conn(ctx, r.db).Preload("Order.Restaurant").Preload("Order.OrderItems.Product").
+		Where("rider_id = ? AND status = ?", riderID, models.AssignmentStatusOffered).
+		Find(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (r *repository) FindAssignmentsByOrderID(ctx context.Context, orderID uint) ([]*models.RiderAssignment, error) {
+	var assignments []*models.RiderAssignment
+	if err := database.Conn(ctx, r.db).Where("order_id = ?", orderID).Order("created_at").Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (r *repository) FindExpiredAssignments(ctx context.Context, now time.Time) ([]*models.RiderAssignment, error) {
+	var assignments []*models.RiderAssignment
+	err := database.Conn(ctx, r.db).Where("status = ? AND expires_at < ?", models.AssignmentStatusOffered, now).
+		Find(&assignments).Error
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
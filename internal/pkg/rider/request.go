@@ -0,0 +1,33 @@
+package rider
+
+type RegisterRequest struct {
+	UserID       uint   `json:"-"`
+	VehicleType  string `json:"vehicle_type" validate:"required"`
+	LicensePlate string `json:"license_plate" validate:"required"`
+}
+
+type StatusRequest struct {
+	UserID uint   `json:"-"`
+	Status string `json:"status" validate:"required,oneof=online offline"`
+}
+
+type LocationRequest struct {
+	UserID    uint    `json:"-"`
+	Latitude  float64 `json:"latitude" validate:"required,latitude"`
+	Longitude float64 `json:"longitude" validate:"required,longitude"`
+}
+
+type OfferRequest struct {
+	UserID       uint `json:"-"`
+	AssignmentID uint `json:"-" path:"id"`
+}
+
+type OrderStatusRequest struct {
+	UserID  uint   `json:"-"`
+	OrderID uint   `json:"-" path:"id"`
+	Status  string `json:"status" validate:"required,oneof=picked_up delivered"`
+}
+
+type GetRequest struct {
+	UserID uint `json:"-"`
+}
@@ -0,0 +1,292 @@
+package rider
+
+import (
+	"context"
+	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/pubsub"
+	"food-delivery-workshop/internal/core/tracing"
+	"food-delivery-workshop/internal/models"
+	"food-delivery-workshop/internal/pkg/order"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	Register(ctx context.Context, request *RegisterRequest) (*models.Rider, error)
+	GetRider(ctx context.Context, request *GetRequest) (*models.Rider, error)
+	UpdateStatus(ctx context.Context, request *StatusRequest) (*models.Rider, error)
+	UpdateLocation(ctx context.Context, request *LocationRequest) (*models.Rider, error)
+	GetOffers(ctx context.Context, request *GetRequest) ([]*models.RiderAssignment, error)
+	AcceptOffer(ctx context.Context, request *OfferRequest) (*models.RiderAssignment, error)
+	DeclineOffer(ctx context.Context, request *OfferRequest) (*models.RiderAssignment, error)
+	UpdateOrderStatus(ctx context.Context, request *OrderStatusRequest) (*models.Order, error)
+	DispatchOrders(ctx context.Context) error
+}
+
+type service struct {
+	repo         Repository
+	orderRepo    order.Repository
+	orderService order.Service
+	hub          *pubsub.Hub
+	offerTimeout time.Duration
+	dispatchMu   sync.Mutex
+}
+
+func NewService(repo Repository, orderRepo order.Repository, orderService order.Service, hub *pubsub.Hub, offerTimeout time.Duration) Service {
+	return &service{repo: repo, orderRepo: orderRepo, orderService: orderService, hub: hub, offerTimeout: offerTimeout}
+}
+
+func (s *service) Register(ctx context.Context, request *RegisterRequest) (*models.Rider, error) {
+	ctx, span := tracing.Start(ctx, "rider.Register")
+	defer span.End()
+
+	existingRider, err := s.repo.FindByUserID(ctx, request.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logrus.WithContext(ctx).Errorf("find rider error: %v", err)
+		return nil, err
+	}
+	if existingRider != nil {
+		return nil, apperror.Conflict("RIDER_EXISTS", "rider already exists")
+	}
+
+	rider := &models.Rider{
+		UserID:       request.UserID,
+		VehicleType:  request.VehicleType,
+		LicensePlate: request.LicensePlate,
+		Status:       models.RiderStatusOffline,
+	}
+	if err := s.repo.Create(ctx, rider); err != nil {
+		logrus.WithContext(ctx).Errorf("create rider error: %v", err)
+		return nil, err
+	}
+
+	return rider, nil
+}
+
+func (s *service) GetRider(ctx context.Context, request *GetRequest) (*models.Rider, error) {
+	ctx, span := tracing.Start(ctx, "rider.GetRider")
+	defer span.End()
+
+	return s.findRider(ctx, request.UserID)
+}
+
+func (s *service) UpdateStatus(ctx context.Context, request *StatusRequest) (*models.Rider, error) {
+	ctx, span := tracing.Start(ctx, "rider.UpdateStatus")
+	defer span.End()
+
+	rider, err := s.findRider(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	rider.Status = request.Status
+	if err := s.repo.Update(ctx, rider); err != nil {
+		logrus.WithContext(ctx).Errorf("update rider status error: %v", err)
+		return nil, err
+	}
+
+	return rider, nil
+}
+
+func (s *service) UpdateLocation(ctx context.Context, request *LocationRequest) (*models.Rider, error) {
+	ctx, span := tracing.Start(ctx, "rider.UpdateLocation")
+	defer span.End()
+
+	rider, err := s.findRider(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rider.Latitude = request.Latitude
+	rider.Longitude = request.Longitude
+	rider.LocationUpdatedAt = &now
+	if err := s.repo.Update(ctx, rider); err != nil {
+		logrus.WithContext(ctx).Errorf("update rider location error: %v", err)
+		return nil, err
+	}
+
+	orders, err := s.orderRepo.FindActiveByRiderID(ctx, rider.ID)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find active orders error: %v", err)
+		return nil, err
+	}
+	for _, o := range orders {
+		s.hub.Publish(order.Topic(o.ID), order.EventRiderLocation, &order.RiderEvent{
+			OrderID:   o.ID,
+			RiderID:   rider.ID,
+			Latitude:  rider.Latitude,
+			Longitude: rider.Longitude,
+			UpdatedAt: now,
+		})
+	}
+
+	return rider, nil
+}
+
+func (s *service) GetOffers(ctx context.Context, request *GetRequest) ([]*models.RiderAssignment, error) {
+	ctx, span := tracing.Start(ctx, "rider.GetOffers")
+	defer span.End()
+
+	rider, err := s.findRider(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.repo.FindPendingAssignmentsByRiderID(ctx, rider.ID)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find pending assignments error: %v", err)
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+func (s *service) AcceptOffer(ctx context.Context, request *OfferRequest) (*models.RiderAssignment, error) {
+	ctx, span := tracing.Start(ctx, "rider.AcceptOffer")
+	defer span.End()
+
+	rider, assignment, err := s.findOpenOffer(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	// ปิด offer ก่อนผูกออเดอร์ ถ้า offer หมดเวลาหรือถูกปิดไปแล้วจะได้ไม่ผูกซ้อนกับไรเดอร์คนอื่น
+	if err := s.respond(ctx, assignment, models.AssignmentStatusAccepted); err != nil {
+		return nil, err
+	}
+
+	if err := s.orderRepo.AssignRider(ctx, assignment.OrderID, rider.ID); err != nil {
+		logrus.WithContext(ctx).Errorf("assign rider error: %v", err)
+		assignment.Status = models.AssignmentStatusExpired
+		if err := s.repo.UpdateAssignment(ctx, assignment); err != nil {
+			logrus.WithContext(ctx).Errorf("update assignment error: %v", err)
+			return nil, err
+		}
+		return nil, apperror.Conflict("ORDER_UNAVAILABLE", "order is no longer available")
+	}
+
+	s.hub.Publish(order.Topic(assignment.OrderID), order.EventRiderAssigned, &order.RiderEvent{
+		OrderID:   assignment.OrderID,
+		RiderID:   rider.ID,
+		Latitude:  rider.Latitude,
+		Longitude: rider.Longitude,
+		UpdatedAt: time.Now(),
+	})
+	return assignment, nil
+}
+
+func (s *service) DeclineOffer(ctx context.Context, request *OfferRequest) (*models.RiderAssignment, error) {
+	ctx, span := tracing.Start(ctx, "rider.DeclineOffer")
+	defer span.End()
+
+	_, assignment, err := s.findOpenOffer(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.respond(ctx, assignment, models.AssignmentStatusDeclined); err != nil {
+		return nil, err
+	}
+
+	// เสนองานให้ไรเดอร์คนถัดไปทันที ไม่ต้องรอรอบถัดไปของ dispatcher
+	if err := s.DispatchOrders(ctx); err != nil {
+		logrus.WithContext(ctx).Errorf("dispatch orders error: %v", err)
+	}
+
+	return assignment, nil
+}
+
+// UpdateOrderStatus lets the rider assigned to an order mark it picked up or
+// delivered. Orders of other riders are reported as not found.
+func (s *service) UpdateOrderStatus(ctx context.Context, request *OrderStatusRequest) (*models.Order, error) {
+	ctx, span := tracing.Start(ctx, "rider.UpdateOrderStatus")
+	defer span.End()
+
+	rider, err := s.findRider(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	assigned, err := s.orderRepo.FindByID(ctx, request.OrderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("ORDER_NOT_FOUND", "order not found")
+		}
+		logrus.WithContext(ctx).Errorf("find order error: %v", err)
+		return nil, err
+	}
+	if assigned.RiderID == nil || *assigned.RiderID != rider.ID {
+		return nil, apperror.NotFound("ORDER_NOT_FOUND", "order not found")
+	}
+
+	return s.orderService.UpdateStatus(ctx, &order.UpdateStatusRequest{ID: assigned.ID, Status: request.Status})
+}
+
+func (s *service) findRider(ctx context.Context, userID uint) (*models.Rider, error) {
+	rider, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("RIDER_NOT_FOUND", "rider not found")
+		}
+		logrus.WithContext(ctx).Errorf("find rider error: %v", err)
+		return nil, err
+	}
+	return rider, nil
+}
+
+func (s *service) findOpenOffer(ctx context.Context, request *OfferRequest) (*models.Rider, *models.RiderAssignment, error) {
+	rider, err := s.findRider(ctx, request.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assignment, err := s.repo.FindAssignmentByID(ctx, request.AssignmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, apperror.NotFound("OFFER_NOT_FOUND", "offer not found")
+		}
+		logrus.WithContext(ctx).Errorf("find assignment error: %v", err)
+		return nil, nil, err
+	}
+
+	if assignment.RiderID != rider.ID {
+		return nil, nil, apperror.NotFound("OFFER_NOT_FOUND", "offer not found")
+	}
+
+	if assignment.Status != models.AssignmentStatusOffered {
+		return nil, nil, apperror.Conflict("OFFER_CLOSED", "offer is no longer open")
+	}
+
+	if time.Now().After(assignment.ExpiresAt) {
+		// OFFER_CLOSED แปลว่า dispatcher ปิดให้แล้ว
+		if err := s.respond(ctx, assignment, models.AssignmentStatusExpired); err != nil && !errors.Is(err, errOfferClosed()) {
+			return nil, nil, err
+		}
+		return nil, nil, apperror.Conflict("OFFER_EXPIRED", "offer has expired")
+	}
+
+	return rider, assignment, nil
+}
+
+// respond closes the offer, or returns OFFER_CLOSED when someone else closed it first.
+func (s *service) respond(ctx context.Context, assignment *models.RiderAssignment, status string) error {
+	if err := s.repo.CloseAssignment(ctx, assignment, status, time.Now()); err != nil {
+		if !errors.Is(err, errOfferClosed()) {
+			logrus.WithContext(ctx).Errorf("update assignment error: %v", err)
+		}
+		return err
+	}
+
+	logrus.WithContext(ctx).WithFields(logrus.Fields{
+		"assignment_id": assignment.ID,
+		"order_id":      assignment.OrderID,
+		"rider_id":      assignment.RiderID,
+		"status":        status,
+	}).Info("rider assignment updated")
+	return nil
+}
@@ -0,0 +1,96 @@
+package sso
+
+import (
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/validation"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// stateCookie ties the login flow to the browser that started it.
+const stateCookie = "oidc_state"
+
+// Login Start social login
+// @Summary Start social login
+// @Description Redirect to the identity provider (e.g. google, line) using the authorization code flow with PKCE. Sets the oidc_state cookie that the callback checks.
+// @Tags auth
+// @Produce  json
+// @Param provider path string true "Provider name"
+// @Success 302 {string} string "redirect to the identity provider"
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Router /auth/{provider}/login [get]
+func Login(c *fiber.Ctx, service Service) error {
+	provider := c.Params("provider")
+	login, err := service.Login(c.UserContext(), &LoginRequest{Provider: provider})
+	if err != nil {
+		return err
+	}
+
+	// SameSite=Lax ยังส่ง cookie ตอน provider redirect กลับมาที่ callback
+	c.Cookie(&fiber.Cookie{
+		Name:     stateCookie,
+		Value:    login.State,
+		Path:     "/auth/" + provider,
+		MaxAge:   int(loginStateTTL / time.Second),
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Redirect(login.AuthURL, fiber.StatusFound)
+}
+
+// Callback Finish social login
+// @Summary Finish social login
+// @Description Called by the identity provider. Requires the oidc_state cookie set by login. Verifies the ID token, links the identity to the account with the same verified email (or creates one) and returns an access token.
+// @Tags auth
+// @Produce  json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Router /auth/{provider}/callback [get]
+func Callback(c *fiber.Ctx, service Service) error {
+	if reason := c.Query("error"); reason != "" {
+		return apperror.Unauthorized("SOCIAL_LOGIN_DENIED", "the identity provider returned an error").
+			WithDetails(map[string]interface{}{"error": reason, "error_description": c.Query("error_description")})
+	}
+
+	request, err := validation.BindAndValidate[CallbackRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.Provider = c.Params("provider")
+	request.BrowserState = c.Cookies(stateCookie)
+	// state ใช้ได้ครั้งเดียว ลบ cookie ทิ้งไม่ว่าผลจะเป็นอย่างไร
+	c.Cookie(&fiber.Cookie{
+		Name:     stateCookie,
+		Path:     "/auth/" + request.Provider,
+		Expires:  time.Unix(0, 0),
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	user, err := service.Callback(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	token, err := auth.GenerateJWT(user.ID, user.Roles)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"access_token": token,
+	})
+}
@@ -0,0 +1,42 @@
+package sso
+
+import (
+	"context"
+	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	FindIdentity(ctx context.Context, provider string, subject string, identity *models.UserIdentity) error
+	CreateIdentity(ctx context.Context, identity *models.UserIdentity) error
+	CreateUserWithIdentity(ctx context.Context, user *models.User, identity *models.UserIdentity) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindIdentity(ctx context.Context, provider string, subject string, identity *models.UserIdentity) error {
+	return database.Conn(ctx, r.db).Where("provider = ? AND subject = ?", provider, subject).First(identity).Error
+}
+
+func (r *repository) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	return database.Conn(ctx, r.db).Create(identity).Error
+}
+
+func (r *repository) CreateUserWithIdentity(ctx context.Context, user *models.User, identity *models.UserIdentity) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
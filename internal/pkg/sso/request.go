@@ -0,0 +1,17 @@
+package sso
+
+type LoginRequest struct {
+	Provider string `json:"-"`
+}
+
+type LoginResponse struct {
+	AuthURL string
+	State   string // endpoint เก็บไว้ใน cookie เพื่อผูก flow กับ browser ที่เริ่ม login
+}
+
+type CallbackRequest struct {
+	Provider     string `query:"-"`
+	Code         string `query:"code" validate:"required"`
+	State        string `query:"state" validate:"required"`
+	BrowserState string `query:"-"` // state จาก cookie ของ browser ที่เรียก callback
+}
@@ -0,0 +1,167 @@
+package sso
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/oidc"
+	"food-delivery-workshop/internal/core/tracing"
+	"food-delivery-workshop/internal/models"
+	"food-delivery-workshop/internal/pkg/user"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	Login(ctx context.Context, request *LoginRequest) (*LoginResponse, error)
+	Callback(ctx context.Context, request *CallbackRequest) (*models.User, error)
+}
+
+const loginStateTTL = 10 * time.Minute
+
+type service struct {
+	repo      Repository
+	userRepo  user.Repository
+	providers map[string]*oidc.Provider
+	states    oidc.StateStore
+}
+
+func NewService(repo Repository, userRepo user.Repository, providers []*oidc.Provider, states oidc.StateStore) Service {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+	return &service{repo: repo, userRepo: userRepo, providers: byName, states: states}
+}
+
+// Login returns the provider URL to redirect the browser to, and the state the
+// browser must present again at the callback.
+func (s *service) Login(ctx context.Context, request *LoginRequest) (*LoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "sso.Login")
+	defer span.End()
+
+	provider, err := s.provider(request.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, flow, err := oidc.NewFlow(provider.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.states.Save(state, flow, loginStateTTL); err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, flow.Nonce, flow.Verifier)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("oidc discovery error: %v", err)
+		return nil, apperror.Internal(err)
+	}
+	return &LoginResponse{AuthURL: authURL, State: state}, nil
+}
+
+// Callback finishes the login and returns the user the identity belongs to,
+// linking it to an existing account with the same verified email or creating
+// a new account on first sign in.
+func (s *service) Callback(ctx context.Context, request *CallbackRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "sso.Callback")
+	defer span.End()
+
+	provider, err := s.provider(request.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// state ต้องตรงกับ cookie ของ browser ที่เริ่ม login กันการส่งลิงก์ callback ของคนอื่นมาให้ (login CSRF)
+	if request.BrowserState == "" || subtle.ConstantTimeCompare([]byte(request.BrowserState), []byte(request.State)) != 1 {
+		return nil, apperror.Unauthorized("INVALID_LOGIN_STATE", "login session is invalid or expired, please start again")
+	}
+
+	flow, err := s.states.Take(request.State)
+	if err != nil || flow.Provider != provider.Name() {
+		return nil, apperror.Unauthorized("INVALID_LOGIN_STATE", "login session is invalid or expired, please start again")
+	}
+
+	identity, err := provider.Exchange(ctx, request.Code, flow.Verifier, flow.Nonce)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("oidc exchange error: %v", err)
+		return nil, apperror.Unauthorized("SOCIAL_LOGIN_FAILED", "could not verify the identity provider response")
+	}
+
+	linked := &models.UserIdentity{}
+	err = s.repo.FindIdentity(ctx, identity.Provider, identity.Subject, linked)
+	if err == nil {
+		return s.findUser(ctx, linked.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logrus.WithContext(ctx).Errorf("find identity error: %v", err)
+		return nil, err
+	}
+
+	// ผูกบัญชีด้วยอีเมลได้เฉพาะเมื่อ provider ยืนยันอีเมลแล้วเท่านั้น
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, apperror.Forbidden("EMAIL_NOT_VERIFIED", "the identity provider did not return a verified email")
+	}
+
+	linked = &models.UserIdentity{Provider: identity.Provider, Subject: identity.Subject, Email: identity.Email}
+	existing := &models.User{}
+	err = s.userRepo.FindByEmail(ctx, identity.Email, existing)
+	if err == nil {
+		// ถ้าบัญชีเดิมยังไม่ยืนยันอีเมล อาจเป็นคนอื่นสมัครดักไว้ จึงไม่ผูกให้
+		if existing.EmailVerifiedAt == nil {
+			return nil, apperror.Conflict("ACCOUNT_NOT_VERIFIED", "an account with this email exists but its email is not verified, verify it or sign in with password first")
+		}
+		linked.UserID = existing.ID
+		if err := s.repo.CreateIdentity(ctx, linked); err != nil {
+			logrus.WithContext(ctx).Errorf("create identity error: %v", err)
+			return nil, err
+		}
+		existing.Password = ""
+		return existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logrus.WithContext(ctx).Errorf("find user by email error: %v", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	newUser := &models.User{
+		FirstName:       identity.GivenName,
+		LastName:        identity.FamilyName,
+		Email:           identity.Email,
+		EmailVerifiedAt: &now,
+	}
+	if newUser.FirstName == "" {
+		newUser.FirstName = identity.Name
+	}
+	if err := s.repo.CreateUserWithIdentity(ctx, newUser, linked); err != nil {
+		logrus.WithContext(ctx).Errorf("create user with identity error: %v", err)
+		return nil, err
+	}
+	return newUser, nil
+}
+
+func (s *service) provider(name string) (*oidc.Provider, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, apperror.NotFound("PROVIDER_NOT_FOUND", "unknown login provider")
+	}
+	return provider, nil
+}
+
+func (s *service) findUser(ctx context.Context, userID uint) (*models.User, error) {
+	found := &models.User{}
+	if err := s.userRepo.FindByID(ctx, userID, found); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.Unauthorized("SOCIAL_LOGIN_FAILED", "the linked account no longer exists")
+		}
+		logrus.WithContext(ctx).Errorf("find user error: %v", err)
+		return nil, err
+	}
+	found.Password = ""
+	return found, nil
+}
@@ -1,10 +1,12 @@
 package user
 
 import (
+	"food-delivery-workshop/internal/apperror"
 	"food-delivery-workshop/internal/auth"
-	"net/http"
 	"github.com/gofiber/fiber/v2"
 	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/validation"
+	"strconv"
 )
 
 // RegisterUser register
@@ -14,29 +16,26 @@ import (
 // @Accept  json
 // @Produce  json
 // @Param user body CreateRequest true "User Registration"
-// @Success 201 {object} CreateRequest
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Success 201 {object} models.User
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Failure 429 {object} middleware.ErrorResponse
 // @Router /users/register [post]
 func Register(c *fiber.Ctx, service Service) error {
-	request := &CreateRequest{}
-	if err := c.BodyParser(&request); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid input",
-		})
+	request, err := validation.BindAndValidate[CreateRequest](c)
+	if err != nil {
+		return err
 	}
 
-	err := service.Create(c, request)
+	user, err := service.Create(c.UserContext(), request)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Server error: " + err.Error(),
-		})
+		return err
 	}
-	request.Password = ""
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Create user success",
-		"user":    request,
+		"user":    user,
 	})
 }
 
@@ -48,29 +47,83 @@ func Register(c *fiber.Ctx, service Service) error {
 // @Produce  json
 // @Param login body LoginRequest true "Login Data"
 // @Success 200 {object} fiber.Map
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 429 {object} middleware.ErrorResponse
 // @Router /users/login [post]
 func Login(c *fiber.Ctx, service Service) error {
-	request := &LoginRequest{}
-	if err := c.BodyParser(request); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	request, err := validation.BindAndValidate[LoginRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.IP = c.IP()
+	request.UserAgent = c.Get(fiber.HeaderUserAgent)
+	user, err := service.Login(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	token, err := auth.GenerateJWT(user.ID, user.Roles)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"access_token": token,
+	})
+}
+
+// RequestOTP Request login OTP
+// @Summary Request a login code by SMS
+// @Description Send a 6 digit login code to the registered mobile number
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body OTPRequest true "Mobile number"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 429 {object} middleware.ErrorResponse
+// @Router /users/otp/request [post]
+func RequestOTP(c *fiber.Ctx, service Service) error {
+	request, err := validation.BindAndValidate[OTPRequest](c)
+	if err != nil {
+		return err
+	}
+
+	if err := service.RequestOTP(c.UserContext(), request); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "If the number is registered, a code has been sent",
+	})
+}
+
+// VerifyOTP Login with OTP
+// @Summary Log in with an SMS code
+// @Description Exchange the code for the same access token as /users/login
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body VerifyOTPRequest true "Mobile number and code"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 429 {object} middleware.ErrorResponse
+// @Router /users/otp/verify [post]
+func VerifyOTP(c *fiber.Ctx, service Service) error {
+	request, err := validation.BindAndValidate[VerifyOTPRequest](c)
+	if err != nil {
+		return err
 	}
 
-	user, err := service.Login(request)
+	user, err := service.VerifyOTP(c.UserContext(), request)
 	if err != nil {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid email or password",
-		})
+		return err
 	}
 
-	token, err := auth.GenerateJWT(user.ID)
+	token, err := auth.GenerateJWT(user.ID, user.Roles)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate token",
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -85,35 +138,317 @@ func Login(c *fiber.Ctx, service Service) error {
 // @Accept  json
 // @Produce  json
 // @Success 200 {object} models.User "User profile"
-// @Failure 401 {object} map[string]string
-// @Failure 404 {object} map[string]string
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
 // @Security ApiKeyAuth
 // @Router /me [get]
 func GetUserByID(c *fiber.Ctx, service Service) error {
-	authUserID := c.Locals("user_id")
-	if authUserID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Unauthorized",
-		})
-	}
-
-	authUserIDUint, ok := authUserID.(float64)
-	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid user token",
-		})
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
 	}
 
 	request := get.GetOne[uint]{
-		ID: uint(authUserIDUint),
+		ID: principal.UserID,
 	}
-	user, err := service.GetUserByID(request)
+	user, err := service.GetUserByID(c.UserContext(), request)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "User not found",
-		})
+		return err
 	}
 	user.Password = ""
 	return c.Status(fiber.StatusOK).JSON(user)
 
 }
+
+// UpdateProfile Update profile
+// @Summary Update profile
+// @Description Update name, phone or address. Only the fields sent are changed. The phone must not belong to another account.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body UpdateProfileRequest true "Profile fields"
+// @Success 200 {object} models.User
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /me [patch]
+func UpdateProfile(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := validation.BindAndValidate[UpdateProfileRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.ID = principal.UserID
+	user, err := service.UpdateProfile(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// ChangePassword Change password
+// @Summary Change password
+// @Description Change the password. The current password is required.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body ChangePasswordRequest true "Passwords"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /me/password [post]
+func ChangePassword(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := validation.BindAndValidate[ChangePasswordRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.ID = principal.UserID
+	if err := service.ChangePassword(c.UserContext(), request); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Password changed successfully",
+	})
+}
+
+// RequestEmailChange Change email
+// @Summary Request an email change
+// @Description Send a confirmation token for the new address. The email changes only after it is confirmed.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body ChangeEmailRequest true "New email"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /me/email [post]
+func RequestEmailChange(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := validation.BindAndValidate[ChangeEmailRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.ID = principal.UserID
+	if err := service.RequestEmailChange(c.UserContext(), request); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Confirmation sent to the new email address",
+	})
+}
+
+// ConfirmEmailChange Confirm email
+// @Summary Confirm an email change
+// @Description Apply a pending email change with the token sent to the new address
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body ConfirmEmailRequest true "Confirmation token"
+// @Success 200 {object} models.User
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /me/email/confirm [post]
+func ConfirmEmailChange(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := validation.BindAndValidate[ConfirmEmailRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.ID = principal.UserID
+	user, err := service.ConfirmEmailChange(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// DeleteAccount Close account
+// @Summary Close account
+// @Description Close the account. Personal data is removed; order history is kept.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body DeleteAccountRequest true "Current password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /me [delete]
+func DeleteAccount(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := validation.BindAndValidate[DeleteAccountRequest](c)
+	if err != nil {
+		return err
+	}
+
+	request.ID = principal.UserID
+	if err := service.DeleteAccount(c.UserContext(), request); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Account deleted successfully",
+	})
+}
+
+// ForgotPassword Forgot password
+// @Summary Request a password reset
+// @Description Email a single-use reset code. Always succeeds so it does not reveal which emails are registered.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body ForgotPasswordRequest true "Email"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 429 {object} middleware.ErrorResponse
+// @Router /users/password/forgot [post]
+func ForgotPassword(c *fiber.Ctx, service Service) error {
+	request, err := validation.BindAndValidate[ForgotPasswordRequest](c)
+	if err != nil {
+		return err
+	}
+
+	if err := service.ForgotPassword(c.UserContext(), request); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "If the email is registered, a reset code has been sent",
+	})
+}
+
+// ResetPassword Reset password
+// @Summary Reset password
+// @Description Set a new password with the code from the reset email
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body ResetPasswordRequest true "Reset code and new password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 429 {object} middleware.ErrorResponse
+// @Router /users/password/reset [post]
+func ResetPassword(c *fiber.Ctx, service Service) error {
+	request, err := validation.BindAndValidate[ResetPasswordRequest](c)
+	if err != nil {
+		return err
+	}
+
+	if err := service.ResetPassword(c.UserContext(), request); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Password reset successfully",
+	})
+}
+
+// VerifyEmail Verify email
+// @Summary Verify email address
+// @Description Mark the email as verified with the code from the verification email
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body VerifyEmailRequest true "Verification code"
+// @Success 200 {object} models.User
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Router /users/email/verify [post]
+func VerifyEmail(c *fiber.Ctx, service Service) error {
+	request, err := validation.BindAndValidate[VerifyEmailRequest](c)
+	if err != nil {
+		return err
+	}
+
+	user, err := service.VerifyEmail(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// SendVerification Resend verification
+// @Summary Resend the verification email
+// @Description Send a new verification code to the current email address
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Success 202 {object} map[string]string
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /me/email/verification [post]
+func SendVerification(c *fiber.Ctx, service Service) error {
+	principal, err := auth.CurrentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if err := service.SendVerification(c.UserContext(), &get.GetOne[uint]{ID: principal.UserID}); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Verification email sent",
+	})
+}
+
+// Unlock Unlock account
+// @Summary Unlock a user account
+// @Description Clear the failed login counter so the user can log in again right away (admin)
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /admin/users/{id}/unlock [post]
+func Unlock(c *fiber.Ctx, service Service) error {
+	userID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid user ID")
+	}
+
+	if err := service.Unlock(c.UserContext(), get.GetOne[uint]{ID: uint(userID)}); err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Account unlocked",
+	})
+}
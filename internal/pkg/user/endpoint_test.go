@@ -0,0 +1,113 @@
+package user_test
+
+import (
+	"context"
+	"fmt"
+	"food-delivery-workshop/internal/auth"
+	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/core/pii"
+	routes "food-delivery-workshop/internal/middleware"
+	"food-delivery-workshop/internal/models"
+	"food-delivery-workshop/internal/pkg/user"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB connects to TEST_DATABASE_DSN, e.g.
+// "host=localhost user=postgres password=1234 dbname=food_delivery_test sslmode=disable".
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := db.AutoMigrate(database.Models...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	t.Setenv("PII_DEV_KEYS", "true")
+	keyring, err := pii.LoadKeyring()
+	if err != nil {
+		t.Fatalf("load keyring: %v", err)
+	}
+	pii.SetKeyring(keyring)
+	return db
+}
+
+func TestDeleteAccountScrubsOrdersAndLoginAttempts(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	password, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	email := fmt.Sprintf("delete-me-%d@example.com", time.Now().UnixNano())
+	account := &models.User{FirstName: "Somchai", LastName: "Jaidee", Email: email, Password: string(password),
+		Address: "99 Sukhumvit Rd", AddressDetails: "Room 12"}
+	if err := db.Create(account).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	restaurant := &models.Restaurant{Name: "Test Kitchen"}
+	if err := db.Create(restaurant).Error; err != nil {
+		t.Fatalf("create restaurant: %v", err)
+	}
+	order := &models.Order{UserID: account.ID, RestaurantID: restaurant.ID, Status: models.OrderStatusDelivered,
+		DeliveryAddress: "99 Sukhumvit Rd", DeliveryLatitude: 13.73, DeliveryLongitude: 100.56}
+	if err := db.Create(order).Error; err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	attempts := []*models.LoginAttempt{
+		{UserID: &account.ID, Email: email, IP: "203.0.113.7", UserAgent: "curl/8.0", Reason: models.LoginFailureInvalidPassword},
+		{Email: email, IP: "203.0.113.7", UserAgent: "curl/8.0", Reason: models.LoginFailureUnknownEmail},
+	}
+	if err := db.Create(attempts).Error; err != nil {
+		t.Fatalf("create login attempts: %v", err)
+	}
+
+	service := user.NewService(user.NewRepository(db), nil, nil, nil, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: routes.ErrorHandler})
+	app.Delete("/me", func(c *fiber.Ctx) error {
+		c.SetUserContext(auth.NewContext(c.UserContext(), &auth.Principal{UserID: account.ID}))
+		return user.DeleteAccount(c, service)
+	})
+
+	req := httptest.NewRequest(fiber.MethodDelete, "/me", strings.NewReader(`{"password":"secret123"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("DELETE /me: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("DELETE /me status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	scrubbed := &models.Order{}
+	if err := db.WithContext(ctx).First(scrubbed, order.ID).Error; err != nil {
+		t.Fatalf("find order: %v", err)
+	}
+	if scrubbed.DeliveryAddress != "" || scrubbed.DeliveryLatitude != 0 || scrubbed.DeliveryLongitude != 0 {
+		t.Errorf("order delivery location = %q (%v, %v), want blank", scrubbed.DeliveryAddress.Plain(),
+			scrubbed.DeliveryLatitude, scrubbed.DeliveryLongitude)
+	}
+
+	var remaining int64
+	if err := db.WithContext(ctx).Unscoped().Model(&models.LoginAttempt{}).
+		Where("user_id = ? OR email = ?", account.ID, email).
+		Count(&remaining).Error; err != nil {
+		t.Fatalf("count login attempts: %v", err)
+	}
+	if remaining != 0 {
+		t.Errorf("login attempts left = %d, want 0", remaining)
+	}
+}
@@ -0,0 +1,34 @@
+package user
+
+import (
+	"fmt"
+	"food-delivery-workshop/internal/core/mailer"
+	"time"
+)
+
+func verificationMail(to string, token string, ttl time.Duration) *mailer.Message {
+	return &mailer.Message{
+		To:      to,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Use this code to verify your email address:\n\n%s\n\nThe code expires in %s.",
+			token, ttl),
+	}
+}
+
+func emailChangeMail(to string, token string, ttl time.Duration) *mailer.Message {
+	return &mailer.Message{
+		To:      to,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Use this code to confirm your new email address:\n\n%s\n\nThe code expires in %s. If you did not ask to change your email, ignore this message.",
+			token, ttl),
+	}
+}
+
+func passwordResetMail(to string, token string, ttl time.Duration) *mailer.Message {
+	return &mailer.Message{
+		To:      to,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Use this code to reset your password:\n\n%s\n\nThe code expires in %s and can be used once. If you did not ask for a reset, ignore this message.",
+			token, ttl),
+	}
+}
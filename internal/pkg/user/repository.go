@@ -1,14 +1,33 @@
 package user
 
 import (
+	"context"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/core/pii"
 	"food-delivery-workshop/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
 type Repository interface {
-	Create(user *models.User) error
-	FindByEmail(email string, user *models.User) error
-	FindByID(userID uint, user *models.User) error
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+	FindByEmail(ctx context.Context, email string, user *models.User) error
+	FindByID(ctx context.Context, userID uint, user *models.User) error
+	FindByIDCard(ctx context.Context, idCard string, user *models.User) error
+	FindByPhone(ctx context.Context, phone string, user *models.User) error
+	FindIDsNeedingRotation(ctx context.Context, needsRotation func(value string) bool) ([]uint, error)
+	CreateToken(ctx context.Context, token *models.UserToken) error
+	FindToken(ctx context.Context, purpose string, tokenHash string, token *models.UserToken) error
+	UseToken(ctx context.Context, tokenID uint) error
+	Anonymize(ctx context.Context, user *models.User) error
+	CreateLoginAttempt(ctx context.Context, attempt *models.LoginAttempt) error
+	CreateOTP(ctx context.Context, otp *models.LoginOTP) error
+	FindLatestOTP(ctx context.Context, userID uint, otp *models.LoginOTP) error
+	UseOTPAttempt(ctx context.Context, otpID uint, maxAttempts int) (bool, error)
+	ConsumeOTP(ctx context.Context, otpID uint) error
 }
 
 type repository struct {
@@ -19,23 +38,221 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Create(user *models.User) error {
-	if err := r.db.Create(user).Error; err != nil {
+func (r *repository) Create(ctx context.Context, user *models.User) error {
+	if err := database.Conn(ctx, r.db).Create(user).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *repository) FindByEmail(email string, user *models.User) error {
-	if err := r.db.Where("email = ?", email).First(user).Error; err != nil {
+func (r *repository) Update(ctx context.Context, user *models.User) error {
+	if err := database.Conn(ctx, r.db).Omit(clause.Associations).Save(user).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *repository) FindByID(userID uint, user *models.User) error {
-	if err := r.db.Where("id = ?", userID).First(user).Error; err != nil {
+func (r *repository) FindByEmail(ctx context.Context, email string, user *models.User) error {
+	if err := database.Conn(ctx, r.db).Where("email = ?", email).First(user).Error; err != nil {
 		return err
 	}
 	return nil
 }
+
+func (r *repository) FindByID(ctx context.Context, userID uint, user *models.User) error {
+	if err := database.Conn(ctx, r.db).Where("id = ?", userID).First(user).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindByIDCard looks the user up by blind index, since id_card itself is encrypted.
+func (r *repository) FindByIDCard(ctx context.Context, idCard string, user *models.User) error {
+	if err := database.Conn(ctx, r.db).Where("id_card_index = ?", pii.BlindIndex(idCard)).First(user).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindByPhone returns a PHONE_NOT_UNIQUE error rather than picking one when
+// several users share the phone, since the phone signs them in with an OTP.
+func (r *repository) FindByPhone(ctx context.Context, phone string, user *models.User) error {
+	users := []*models.User{}
+	err := database.Conn(ctx, r.db).Where("phone_index = ?", pii.BlindIndex(phone)).
+		Order("id").Limit(2).Find(&users).Error
+	if err != nil {
+		return err
+	}
+	switch len(users) {
+	case 0:
+		return gorm.ErrRecordNotFound
+	case 1:
+		*user = *users[0]
+		return nil
+	default:
+		return errPhoneNotUnique()
+	}
+}
+
+func errPhoneNotUnique() error {
+	return apperror.Conflict("PHONE_NOT_UNIQUE", "phone number is registered to more than one account")
+}
+
+// FindIDsNeedingRotation reads the raw encrypted columns and returns users
+// with any value that needsRotation reports as stale.
+func (r *repository) FindIDsNeedingRotation(ctx context.Context, needsRotation func(value string) bool) ([]uint, error) {
+	type piiColumns struct {
+		ID             uint
+		Phone          string
+		IDCard         string
+		Address        string
+		AddressDetails string
+	}
+
+	ids := []uint{}
+	var lastID uint
+	for {
+		rows := []piiColumns{}
+		err := database.Conn(ctx, r.db).Model(&models.User{}).
+			Select("id, phone, id_card, address, address_details").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(500).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return ids, nil
+		}
+
+		for _, row := range rows {
+			if needsRotation(row.Phone) || needsRotation(row.IDCard) ||
+				needsRotation(row.Address) || needsRotation(row.AddressDetails) {
+				ids = append(ids, row.ID)
+			}
+		}
+		lastID = rows[len(rows)-1].ID
+	}
+}
+
+func (r *repository) CreateToken(ctx context.Context, token *models.UserToken) error {
+	if err := database.Conn(ctx, r.db).Create(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FindToken(ctx context.Context, purpose string, tokenHash string, token *models.UserToken) error {
+	if err := database.Conn(ctx, r.db).Where("purpose = ? AND token_hash = ?", purpose, tokenHash).First(token).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// UseToken marks the token as used only if nobody used it first.
+func (r *repository) UseToken(ctx context.Context, tokenID uint) error {
+	result := database.Conn(ctx, r.db).Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", tokenID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperror.Conflict("TOKEN_ALREADY_USED", "token has already been used")
+	}
+	return nil
+}
+
+// Anonymize saves the scrubbed user, drops the cart, pending tokens, social
+// login links and failed login records, blanks the delivery address on the
+// user's orders, and soft deletes the account. Orders keep pointing at the user id.
+func (r *repository) Anonymize(ctx context.Context, user *models.User) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// อีเมลเดิมก่อนถูกแทนที่ ใช้ลบ login attempt ที่บันทึกด้วยอีเมลนี้
+		var emails []string
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Pluck("email", &emails).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ? OR email IN ?", user.ID, emails).
+			Delete(&models.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Order{}).Where("user_id = ?", user.ID).Updates(map[string]interface{}{
+			"delivery_address":   "",
+			"delivery_latitude":  0,
+			"delivery_longitude": 0,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(user).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("cart_id IN (?)", tx.Model(&models.Cart{}).Select("id").Where("user_id = ?", user.ID)).
+			Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.Cart{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.UserIdentity{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(user).Error
+	})
+}
+
+func (r *repository) CreateLoginAttempt(ctx context.Context, attempt *models.LoginAttempt) error {
+	if err := database.Conn(ctx, r.db).Create(attempt).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateOTP stores a new code and retires the user's previous ones, so only the latest code works.
+func (r *repository) CreateOTP(ctx context.Context, otp *models.LoginOTP) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.LoginOTP{}).
+			Where("user_id = ? AND consumed_at IS NULL", otp.UserID).
+			Update("consumed_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(otp).Error
+	})
+}
+
+func (r *repository) FindLatestOTP(ctx context.Context, userID uint, otp *models.LoginOTP) error {
+	if err := database.Conn(ctx, r.db).Where("user_id = ?", userID).Order("created_at DESC").First(otp).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// UseOTPAttempt counts a guess before the code is compared, so parallel
+// requests cannot get more than maxAttempts guesses. It reports false once
+// the attempts are used up.
+func (r *repository) UseOTPAttempt(ctx context.Context, otpID uint, maxAttempts int) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&models.LoginOTP{}).
+		Where("id = ? AND attempts < ?", otpID, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *repository) ConsumeOTP(ctx context.Context, otpID uint) error {
+	result := database.Conn(ctx, r.db).Model(&models.LoginOTP{}).
+		Where("id = ? AND consumed_at IS NULL", otpID).
+		Update("consumed_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperror.Validation("INVALID_OTP", "invalid or expired code")
+	}
+	return nil
+}
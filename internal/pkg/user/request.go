@@ -3,10 +3,10 @@ package user
 type Request struct {
 	FirstName      string `json:"first_name" validate:"required"`
     LastName       string `json:"last_name" validate:"required"`
-    Email          string `json:"email" validate:"required"`
-    Password       string `json:"password" validate:"required"`
-    Phone          string `json:"phone"`
-    IDCard         string `json:"id_card"`
+    Email          string `json:"email" validate:"required,email"`
+    Password       string `json:"password" validate:"required,min=10,password"`
+    Phone          string `json:"phone" validate:"required,thai_phone"`
+    IDCard         string `json:"id_card" validate:"required,thai_id"`
     Address        string `json:"address"`
     AddressDetails string `json:"address_details"`
 }
@@ -16,6 +16,61 @@ type CreateRequest struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type UpdateProfileRequest struct {
+	ID             uint    `json:"-"`
+	FirstName      *string `json:"first_name" validate:"omitnil,min=1"`
+	LastName       *string `json:"last_name" validate:"omitnil,min=1"`
+	Phone          *string `json:"phone" validate:"omitnil,thai_phone"`
+	Address        *string `json:"address"`
+	AddressDetails *string `json:"address_details"`
+}
+
+type ChangePasswordRequest struct {
+	ID              uint   `json:"-"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=10,password"`
+}
+
+type ChangeEmailRequest struct {
+	ID       uint   `json:"-"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ConfirmEmailRequest struct {
+	ID    uint   `json:"-"`
+	Token string `json:"token" validate:"required"`
+}
+
+type DeleteAccountRequest struct {
+	ID       uint   `json:"-"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=10,password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type OTPRequest struct {
+	Phone string `json:"phone" validate:"required,thai_phone"`
+}
+
+type VerifyOTPRequest struct {
+	Phone string `json:"phone" validate:"required,thai_phone"`
+	Code  string `json:"code" validate:"required,len=6,numeric"`
 }
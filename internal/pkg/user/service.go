@@ -1,72 +1,701 @@
 package user
 
 import (
+	"context"
 	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/lockout"
+	"food-delivery-workshop/internal/core/mailer"
+	"food-delivery-workshop/internal/core/pii"
+	"food-delivery-workshop/internal/core/sms"
+	"food-delivery-workshop/internal/core/tracing"
 	"food-delivery-workshop/internal/get"
 	"food-delivery-workshop/internal/models"
+	"fmt"
+	"strings"
+	"time"
 
-	"github.com/gofiber/fiber/v2"
 	"github.com/jinzhu/copier"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type Service interface {
-	Create(c *fiber.Ctx, request *CreateRequest) error
-	Login(request *LoginRequest) (*models.User, error)
-	GetUserByID(request get.GetOne[uint]) (*models.User, error)
+	Create(ctx context.Context, request *CreateRequest) (*models.User, error)
+	Login(ctx context.Context, request *LoginRequest) (*models.User, error)
+	GetUserByID(ctx context.Context, request get.GetOne[uint]) (*models.User, error)
+	RotatePII(ctx context.Context) (int, error)
+	UpdateProfile(ctx context.Context, request *UpdateProfileRequest) (*models.User, error)
+	ChangePassword(ctx context.Context, request *ChangePasswordRequest) error
+	RequestEmailChange(ctx context.Context, request *ChangeEmailRequest) error
+	ConfirmEmailChange(ctx context.Context, request *ConfirmEmailRequest) (*models.User, error)
+	DeleteAccount(ctx context.Context, request *DeleteAccountRequest) error
+	ForgotPassword(ctx context.Context, request *ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, request *ResetPasswordRequest) error
+	SendVerification(ctx context.Context, request *get.GetOne[uint]) error
+	VerifyEmail(ctx context.Context, request *VerifyEmailRequest) (*models.User, error)
+	Unlock(ctx context.Context, request get.GetOne[uint]) error
+	RequestOTP(ctx context.Context, request *OTPRequest) error
+	VerifyOTP(ctx context.Context, request *VerifyOTPRequest) (*models.User, error)
 }
 
+const (
+	emailChangeTTL       = 24 * time.Hour
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 48 * time.Hour
+	otpTTL               = 5 * time.Minute
+	otpResendInterval    = time.Minute
+	otpMaxAttempts       = 5
+)
+
 type service struct {
-	repo Repository
+	repo           Repository
+	mailer         mailer.Mailer
+	smsSender      sms.SMSSender
+	accountLimiter *lockout.Limiter
+	ipLimiter      *lockout.Limiter
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, mailer mailer.Mailer, smsSender sms.SMSSender, accountLimiter *lockout.Limiter, ipLimiter *lockout.Limiter) Service {
+	return &service{repo: repo, mailer: mailer, smsSender: smsSender, accountLimiter: accountLimiter, ipLimiter: ipLimiter}
 }
 
-func (s *service) Create(c *fiber.Ctx, request *CreateRequest) error {
-	if err := s.validateRegister(request); err != nil {
-		logrus.Errorf("validate register error: %v", err)
-		return err
+func (s *service) Create(ctx context.Context, request *CreateRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "user.Create")
+	defer span.End()
+
+	if err := s.validateRegister(ctx, request); err != nil {
+		logrus.WithContext(ctx).Errorf("validate register error: %v", err)
+		return nil, err
 	}
 	hashPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
 	if err != nil {
-		logrus.Errorf("hash password error: %v", err)
-		return err
+		logrus.WithContext(ctx).Errorf("hash password error: %v", err)
+		return nil, err
 	}
 	request.Password = string(hashPassword)
 	user := &models.User{}
 	_ = copier.Copy(user, request)
-	err = s.repo.Create(user)
+	err = s.repo.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// สมัครสำเร็จแล้ว ถ้าส่งอีเมลไม่ได้ผู้ใช้ขอส่งใหม่ได้ที่ /me/email/verification
+	if err := s.sendVerification(ctx, user); err != nil {
+		logrus.WithContext(ctx).Errorf("send verification email error: %v", err)
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+func (s *service) Login(ctx context.Context, request *LoginRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "user.Login")
+	defer span.End()
+
+	accountKey := strings.ToLower(strings.TrimSpace(request.Email))
+	if err := s.checkLoginAllowed(ctx, accountKey, request); err != nil {
+		return nil, err
+	}
+
+	user := &models.User{}
+	if err := s.repo.FindByEmail(ctx, request.Email, user); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, s.loginFailed(ctx, accountKey, request, nil, models.LoginFailureUnknownEmail)
+		}
+		logrus.WithContext(ctx).Errorf("find user by email error: %v", err)
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
+		logrus.WithContext(ctx).Warnf("compare password error: %v", err)
+		return nil, s.loginFailed(ctx, accountKey, request, &user.ID, models.LoginFailureInvalidPassword)
+	}
+
+	// ไม่ reset ตัวนับของ IP เพราะ login บัญชีตัวเองสำเร็จไม่ได้แปลว่า IP นั้นไม่ได้เดารหัสบัญชีอื่น
+	// แค่คืนครั้งที่นับไว้ล่วงหน้าสำหรับ request นี้
+	if err := s.accountLimiter.Reset(accountKey); err != nil {
+		logrus.WithContext(ctx).Errorf("reset login attempts error: %v", err)
+	}
+	if err := s.ipLimiter.Release(request.IP); err != nil {
+		logrus.WithContext(ctx).Errorf("release login attempt error: %v", err)
+	}
+	user.Password = ""
+	return user, nil
+}
+
+// Unlock clears the failed login counter of the user's account.
+func (s *service) Unlock(ctx context.Context, request get.GetOne[uint]) error {
+	ctx, span := tracing.Start(ctx, "user.Unlock")
+	defer span.End()
+
+	user, err := s.GetUserByID(ctx, request)
 	if err != nil {
 		return err
 	}
 
+	if err := s.accountLimiter.Reset(strings.ToLower(user.Email)); err != nil {
+		logrus.WithContext(ctx).Errorf("reset login attempts error: %v", err)
+		return err
+	}
 	return nil
 }
 
-func (s *service) Login(request *LoginRequest) (*models.User, error) {
+func (s *service) GetUserByID(ctx context.Context, request get.GetOne[uint]) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "user.GetUserByID")
+	defer span.End()
+
 	user := &models.User{}
-	if err := s.repo.FindByEmail(request.Email, user); err != nil {
-		logrus.Errorf("find user by email error: %v", err)
-		return nil, errors.New("invalid email or password")
+	if err := s.repo.FindByID(ctx, request.GetID(), user); err != nil{
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("USER_NOT_FOUND", "user not found")
+		}
+		logrus.WithContext(ctx).Errorf("find user by id error: %v", err)
+		return nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
-		logrus.Warnf("compare password error: %v", err)
-		return nil, errors.New("invalid email or password")
+	return user, nil
+}
+
+// RotatePII re-encrypts users whose personal data was written with an old key
+// or before encryption was enabled. It returns the number of users updated.
+func (s *service) RotatePII(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "user.RotatePII")
+	defer span.End()
+
+	ids, err := s.repo.FindIDsNeedingRotation(ctx, pii.Current().NeedsRotation)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find users needing rotation error: %v", err)
+		return 0, err
+	}
+
+	for i, id := range ids {
+		user := &models.User{}
+		if err := s.repo.FindByID(ctx, id, user); err != nil {
+			logrus.WithContext(ctx).Errorf("find user by id error: %v", err)
+			return i, err
+		}
+		// Save เข้ารหัสทุกคอลัมน์ใหม่ด้วยคีย์หลักปัจจุบัน
+		if err := s.repo.Update(ctx, user); err != nil {
+			logrus.WithContext(ctx).Errorf("update user error: %v", err)
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+func (s *service) UpdateProfile(ctx context.Context, request *UpdateProfileRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "user.UpdateProfile")
+	defer span.End()
+
+	user, err := s.GetUserByID(ctx, get.GetOne[uint]{ID: request.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	if request.FirstName != nil {
+		user.FirstName = *request.FirstName
+	}
+	if request.LastName != nil {
+		user.LastName = *request.LastName
+	}
+	if request.Phone != nil {
+		if err := s.checkPhoneAvailable(ctx, *request.Phone, user.ID); err != nil {
+			return nil, err
+		}
+		user.Phone = pii.String(*request.Phone)
+	}
+	if request.Address != nil {
+		user.Address = pii.String(*request.Address)
+	}
+	if request.AddressDetails != nil {
+		user.AddressDetails = pii.String(*request.AddressDetails)
+	}
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		logrus.WithContext(ctx).Errorf("update user error: %v", err)
+		return nil, err
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+func (s *service) ChangePassword(ctx context.Context, request *ChangePasswordRequest) error {
+	ctx, span := tracing.Start(ctx, "user.ChangePassword")
+	defer span.End()
+
+	user, err := s.GetUserByID(ctx, get.GetOne[uint]{ID: request.ID})
+	if err != nil {
+		return err
+	}
+
+	if err := checkPassword(user, request.CurrentPassword); err != nil {
+		return err
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("hash password error: %v", err)
+		return err
+	}
+	user.Password = string(hashPassword)
+	if err := s.repo.Update(ctx, user); err != nil {
+		logrus.WithContext(ctx).Errorf("update user error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// RequestEmailChange stores a pending change that takes effect once the token
+// sent to the new address is confirmed.
+func (s *service) RequestEmailChange(ctx context.Context, request *ChangeEmailRequest) error {
+	ctx, span := tracing.Start(ctx, "user.RequestEmailChange")
+	defer span.End()
+
+	user, err := s.GetUserByID(ctx, get.GetOne[uint]{ID: request.ID})
+	if err != nil {
+		return err
+	}
+
+	if err := checkPassword(user, request.Password); err != nil {
+		return err
+	}
+
+	if err := s.checkEmailAvailable(ctx, request.Email); err != nil {
+		return err
+	}
+
+	token, err := s.issueToken(ctx, user.ID, models.UserTokenEmailChange, request.Email, emailChangeTTL)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(emailChangeMail(request.Email, token, emailChangeTTL)); err != nil {
+		logrus.WithContext(ctx).Errorf("send email change mail error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (s *service) ConfirmEmailChange(ctx context.Context, request *ConfirmEmailRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "user.ConfirmEmailChange")
+	defer span.End()
+
+	token, err := s.findToken(ctx, models.UserTokenEmailChange, request.Token)
+	if err != nil {
+		return nil, err
+	}
+	if token.UserID != request.ID {
+		return nil, errInvalidToken()
+	}
+
+	user, err := s.GetUserByID(ctx, get.GetOne[uint]{ID: request.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	// อีเมลอาจถูกใช้สมัครไปแล้วระหว่างรอยืนยัน
+	if err := s.checkEmailAvailable(ctx, token.Email); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UseToken(ctx, token.ID); err != nil {
+		return nil, err
+	}
+
+	// ยืนยันผ่านอีเมลใหม่แล้ว ถือว่าอีเมลนี้ verified
+	now := time.Now()
+	user.Email = token.Email
+	user.EmailVerifiedAt = &now
+	if err := s.repo.Update(ctx, user); err != nil {
+		logrus.WithContext(ctx).Errorf("update user error: %v", err)
+		return nil, err
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+// DeleteAccount closes the account. Personal data is scrubbed but the user
+// row is kept (soft deleted) so order history still resolves.
+func (s *service) DeleteAccount(ctx context.Context, request *DeleteAccountRequest) error {
+	ctx, span := tracing.Start(ctx, "user.DeleteAccount")
+	defer span.End()
+
+	user, err := s.GetUserByID(ctx, get.GetOne[uint]{ID: request.ID})
+	if err != nil {
+		return err
+	}
+
+	if err := checkPassword(user, request.Password); err != nil {
+		return err
+	}
+
+	user.FirstName = "Deleted"
+	user.LastName = "User"
+	user.Email = fmt.Sprintf("deleted-%d@deleted.invalid", user.ID)
+	user.Password = ""
+	user.Phone = ""
+	user.IDCard = ""
+	user.Address = ""
+	user.AddressDetails = ""
+	if err := s.repo.Anonymize(ctx, user); err != nil {
+		logrus.WithContext(ctx).Errorf("anonymize user error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ForgotPassword mails a reset token. It succeeds for unknown emails too so
+// the endpoint cannot be used to find out who has an account.
+func (s *service) ForgotPassword(ctx context.Context, request *ForgotPasswordRequest) error {
+	ctx, span := tracing.Start(ctx, "user.ForgotPassword")
+	defer span.End()
+
+	user := &models.User{}
+	if err := s.repo.FindByEmail(ctx, request.Email, user); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		logrus.WithContext(ctx).Errorf("find user by email error: %v", err)
+		return err
+	}
+
+	token, err := s.issueToken(ctx, user.ID, models.UserTokenPasswordReset, user.Email, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(passwordResetMail(user.Email, token, passwordResetTTL)); err != nil {
+		logrus.WithContext(ctx).Errorf("send password reset mail error: %v", err)
+		return err
 	}
+	return nil
+}
+
+func (s *service) ResetPassword(ctx context.Context, request *ResetPasswordRequest) error {
+	ctx, span := tracing.Start(ctx, "user.ResetPassword")
+	defer span.End()
+
+	token, err := s.findToken(ctx, models.UserTokenPasswordReset, request.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.GetUserByID(ctx, get.GetOne[uint]{ID: token.UserID})
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UseToken(ctx, token.ID); err != nil {
+		return err
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("hash password error: %v", err)
+		return err
+	}
+	user.Password = string(hashPassword)
+	if err := s.repo.Update(ctx, user); err != nil {
+		logrus.WithContext(ctx).Errorf("update user error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (s *service) SendVerification(ctx context.Context, request *get.GetOne[uint]) error {
+	ctx, span := tracing.Start(ctx, "user.SendVerification")
+	defer span.End()
+
+	user, err := s.GetUserByID(ctx, *request)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return apperror.Conflict("EMAIL_ALREADY_VERIFIED", "email is already verified")
+	}
+
+	if err := s.sendVerification(ctx, user); err != nil {
+		logrus.WithContext(ctx).Errorf("send verification email error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (s *service) VerifyEmail(ctx context.Context, request *VerifyEmailRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "user.VerifyEmail")
+	defer span.End()
+
+	token, err := s.findToken(ctx, models.UserTokenEmailVerification, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUserByID(ctx, get.GetOne[uint]{ID: token.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	// token ออกให้อีเมลเดิม ถ้าเปลี่ยนอีเมลไปแล้วจะใช้ไม่ได้
+	if user.Email != token.Email {
+		return nil, errInvalidToken()
+	}
+
+	if err := s.repo.UseToken(ctx, token.ID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := s.repo.Update(ctx, user); err != nil {
+		logrus.WithContext(ctx).Errorf("update user error: %v", err)
+		return nil, err
+	}
+
 	user.Password = ""
 	return user, nil
 }
 
-func (s * service) GetUserByID(request get.GetOne[uint]) (*models.User, error) {
+func (s *service) sendVerification(ctx context.Context, user *models.User) error {
+	token, err := s.issueToken(ctx, user.ID, models.UserTokenEmailVerification, user.Email, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(verificationMail(user.Email, token, emailVerificationTTL))
+}
+
+// issueToken stores the hash of a new single-use token and returns the token itself.
+func (s *service) issueToken(ctx context.Context, userID uint, purpose string, email string, ttl time.Duration) (string, error) {
+	token, tokenHash, err := newToken()
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("generate token error: %v", err)
+		return "", err
+	}
+
+	if err := s.repo.CreateToken(ctx, &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		Email:     email,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		logrus.WithContext(ctx).Errorf("create token error: %v", err)
+		return "", err
+	}
+	return token, nil
+}
+
+// findToken returns the stored token if it exists, is unused and has not expired.
+func (s *service) findToken(ctx context.Context, purpose string, token string) (*models.UserToken, error) {
+	userToken := &models.UserToken{}
+	if err := s.repo.FindToken(ctx, purpose, hashToken(token), userToken); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errInvalidToken()
+		}
+		logrus.WithContext(ctx).Errorf("find token error: %v", err)
+		return nil, err
+	}
+
+	if userToken.UsedAt != nil || time.Now().After(userToken.ExpiresAt) {
+		return nil, errInvalidToken()
+	}
+	return userToken, nil
+}
+
+func errInvalidToken() error {
+	return apperror.Validation("INVALID_TOKEN", "invalid or expired token")
+}
+
+func (s *service) checkEmailAvailable(ctx context.Context, email string) error {
+	exists, err := s.IsEmailExists(ctx, email)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("IsEmailExists is error: %v", err)
+		return err
+	}
+	if exists {
+		return apperror.Conflict("EMAIL_ALREADY_EXISTS", "email already exists")
+	}
+	return nil
+}
+
+func checkPassword(user *models.User, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return apperror.Forbidden("INVALID_PASSWORD", "password is incorrect")
+	}
+	return nil
+}
+
+// RequestOTP texts a 6 digit login code to the phone. Like ForgotPassword it
+// succeeds for unknown numbers so it cannot be used to look up accounts.
+func (s *service) RequestOTP(ctx context.Context, request *OTPRequest) error {
+	ctx, span := tracing.Start(ctx, "user.RequestOTP")
+	defer span.End()
+
 	user := &models.User{}
-	if err := s.repo.FindByID(request.GetID(), user); err != nil{
-		logrus.Errorf("find user by id error: %v", err)
-		return nil, errors.New("user not found")
+	if err := s.repo.FindByPhone(ctx, request.Phone, user); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		logrus.WithContext(ctx).Errorf("find user by phone error: %v", err)
+		return err
+	}
+
+	latest := &models.LoginOTP{}
+	err := s.repo.FindLatestOTP(ctx, user.ID, latest)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logrus.WithContext(ctx).Errorf("find otp error: %v", err)
+		return err
+	}
+	if err == nil {
+		if wait := time.Until(latest.CreatedAt.Add(otpResendInterval)); wait > 0 {
+			return apperror.TooManyRequests("OTP_RESEND_TOO_SOON", "please wait before requesting another code", wait)
+		}
 	}
 
+	code, err := newOTPCode()
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("generate otp error: %v", err)
+		return err
+	}
+	codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("hash otp error: %v", err)
+		return err
+	}
+
+	if err := s.repo.CreateOTP(ctx, &models.LoginOTP{
+		UserID:    user.ID,
+		CodeHash:  string(codeHash),
+		ExpiresAt: time.Now().Add(otpTTL),
+	}); err != nil {
+		logrus.WithContext(ctx).Errorf("create otp error: %v", err)
+		return err
+	}
+
+	message := fmt.Sprintf("รหัส OTP ของคุณคือ %s (หมดอายุใน %d นาที) / Your login code is %s", code, int(otpTTL.Minutes()), code)
+	if err := s.smsSender.Send(user.Phone.Plain(), message); err != nil {
+		logrus.WithContext(ctx).Errorf("send otp sms error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// VerifyOTP checks the latest code for the phone. Each code allows
+// otpMaxAttempts guesses and can be used once.
+func (s *service) VerifyOTP(ctx context.Context, request *VerifyOTPRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "user.VerifyOTP")
+	defer span.End()
+
+	user := &models.User{}
+	if err := s.repo.FindByPhone(ctx, request.Phone, user); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errInvalidOTP()
+		}
+		logrus.WithContext(ctx).Errorf("find user by phone error: %v", err)
+		return nil, err
+	}
+
+	otp := &models.LoginOTP{}
+	if err := s.repo.FindLatestOTP(ctx, user.ID, otp); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errInvalidOTP()
+		}
+		logrus.WithContext(ctx).Errorf("find otp error: %v", err)
+		return nil, err
+	}
+	if otp.ConsumedAt != nil || time.Now().After(otp.ExpiresAt) {
+		return nil, errInvalidOTP()
+	}
+
+	allowed, err := s.repo.UseOTPAttempt(ctx, otp.ID, otpMaxAttempts)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("use otp attempt error: %v", err)
+		return nil, err
+	}
+	if !allowed {
+		return nil, apperror.Validation("OTP_ATTEMPTS_EXCEEDED", "too many wrong codes, request a new one")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(otp.CodeHash), []byte(request.Code)); err != nil {
+		return nil, errInvalidOTP()
+	}
+
+	if err := s.repo.ConsumeOTP(ctx, otp.ID); err != nil {
+		return nil, err
+	}
+
+	user.Password = ""
 	return user, nil
-}
\ No newline at end of file
+}
+
+func errInvalidOTP() error {
+	return apperror.Validation("INVALID_OTP", "invalid or expired code")
+}
+
+// checkLoginAllowed counts the attempt against the account and the IP before
+// the password is compared, so parallel guesses cannot slip past the lockout.
+// Nothing is counted when either is locked.
+func (s *service) checkLoginAllowed(ctx context.Context, accountKey string, request *LoginRequest) error {
+	wait, err := s.accountLimiter.Attempt(accountKey)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("count login attempt error: %v", err)
+		return err
+	}
+	if wait == 0 {
+		wait, err = s.ipLimiter.Attempt(request.IP)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("count login attempt error: %v", err)
+			return err
+		}
+		if wait > 0 {
+			if err := s.accountLimiter.Release(accountKey); err != nil {
+				logrus.WithContext(ctx).Errorf("release login attempt error: %v", err)
+			}
+		}
+	}
+
+	if wait == 0 {
+		return nil
+	}
+	s.auditLoginFailure(ctx, request, nil, models.LoginFailureLocked)
+	return apperror.TooManyRequests("TOO_MANY_LOGIN_ATTEMPTS", "too many failed login attempts, try again later", wait)
+}
+
+// loginFailed returns the error for the client. The failure was already
+// counted by checkLoginAllowed. Unknown emails are counted too so both cases look the same.
+func (s *service) loginFailed(ctx context.Context, accountKey string, request *LoginRequest, userID *uint, reason string) error {
+	s.auditLoginFailure(ctx, request, userID, reason)
+
+	accountWait, err := s.accountLimiter.Check(accountKey)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("check login attempts error: %v", err)
+	}
+	ipWait, err := s.ipLimiter.Check(request.IP)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("check login attempts error: %v", err)
+	}
+
+	if wait := max(accountWait, ipWait); wait > 0 {
+		return apperror.TooManyRequests("TOO_MANY_LOGIN_ATTEMPTS", "too many failed login attempts, try again later", wait)
+	}
+	return apperror.Unauthorized("INVALID_CREDENTIALS", "invalid email or password")
+}
+
+func (s *service) auditLoginFailure(ctx context.Context, request *LoginRequest, userID *uint, reason string) {
+	attempt := &models.LoginAttempt{
+		UserID:    userID,
+		Email:     request.Email,
+		IP:        request.IP,
+		UserAgent: request.UserAgent,
+		Reason:    reason,
+	}
+	if err := s.repo.CreateLoginAttempt(ctx, attempt); err != nil {
+		logrus.WithContext(ctx).Errorf("create login attempt error: %v", err)
+	}
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"email": request.Email, "ip": request.IP, "reason": reason}).Warn("login failed")
+}
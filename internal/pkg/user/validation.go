@@ -1,97 +1,62 @@
 package user
 
 import (
+	"context"
 	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/tracing"
 	"food-delivery-workshop/internal/models"
-	"regexp"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-func (s *service) validateRegister(request *CreateRequest) error {
-	if request.FirstName == "" || request.LastName == "" || request.Email == "" || request.Password == "" {
-		return errors.New("missing required fields")
-	}
-
-	if !isValidEmail(request.Email) {
-		return errors.New("invalid email format")
-	}
-
-	IsEmailExists, err := s.IsEmailExists(request.Email)
-	if err != nil {
-		logrus.Errorf("IsEmailExists is error: %v", err)
+// validateRegister checks what the request tags cannot: that the email, phone and ID card are not taken.
+func (s *service) validateRegister(ctx context.Context, request *CreateRequest) error {
+	if err := s.checkEmailAvailable(ctx, request.Email); err != nil {
 		return err
 	}
 
-	if IsEmailExists {
-		return errors.New("email already exists")
+	if err := s.checkPhoneAvailable(ctx, request.Phone, 0); err != nil {
+		return err
 	}
 
-	if len(request.Password) < 10 {
-		return errors.New("password must be at least 10 characters long")
+	if err := s.repo.FindByIDCard(ctx, request.IDCard, &models.User{}); err == nil {
+		return apperror.Conflict("ID_CARD_ALREADY_EXISTS", "id card already registered")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logrus.WithContext(ctx).Errorf("find user by id card error: %v", err)
+		return err
 	}
 
-	if !hasEnglishLetters(request.Password) {
-		return errors.New("password must contain at least one english letter")
-	}
+	return nil
+}
 
-	if err := validatePhone(request.Phone); err != nil {
-		logrus.Errorf("validate phone error: %v", err)
-		return err
+// checkPhoneAvailable rejects a phone registered to any user other than userID.
+// Phones must be unique because they sign users in with an OTP.
+func (s *service) checkPhoneAvailable(ctx context.Context, phone string, userID uint) error {
+	found := &models.User{}
+	err := s.repo.FindByPhone(ctx, phone, found)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && found.ID == userID) {
+		return nil
 	}
-
-	if err := validateIDCard(request.IDCard); err != nil {
-		logrus.Errorf("validate id card error: %v", err)
+	if err != nil && !errors.Is(err, errPhoneNotUnique()) {
+		logrus.WithContext(ctx).Errorf("find user by phone error: %v", err)
 		return err
 	}
-
-	return nil
+	return apperror.Conflict("PHONE_ALREADY_EXISTS", "phone already registered")
 }
 
-func (s *service) IsEmailExists(email string) (bool, error) {
+func (s *service) IsEmailExists(ctx context.Context, email string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "user.IsEmailExists")
+	defer span.End()
+
 	user := &models.User{}
-	err := s.repo.FindByEmail(email, user)
+	err := s.repo.FindByEmail(ctx, email, user)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
             return false, nil 
         }
-		logrus.Errorf("error find user by email: %v", err)
+		logrus.WithContext(ctx).Errorf("error find user by email: %v", err)
 		return false, err
 	}
 	return true, nil
 }
-
-func hasEnglishLetters(password string) bool {
-	var hasUpper, hasLower bool
-	for _, char := range password {
-		if char >= 'a' && char <= 'z' {
-			hasLower = true
-		} else if char >= 'A' && char <= 'Z' {
-			hasUpper = true
-		}
-	}
-	return hasUpper && hasLower
-}
-
-func isValidEmail(email string) bool {
-	const emailRegex = `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
-	re := regexp.MustCompile(emailRegex)
-	return re.MatchString(email)
-}
-
-func validatePhone(phone string) error {
-	phoneRegex := `^0[689]\d{8}$`
-    re := regexp.MustCompile(phoneRegex)
-    if !re.MatchString(phone) {
-        return errors.New("invalid phone number format")
-    }
-    return nil
-}
-
-func validateIDCard(idCard string) error {
-	idCardRegex := regexp.MustCompile(`^\d{13}$`)
-	if !idCardRegex.MatchString(idCard) {
-		return errors.New("invalid ID card format")
-	}
-	return nil
-}
@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"food-delivery-workshop/internal/core/tracing"
+	"food-delivery-workshop/internal/models"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const maxErrorBody = 512
+
+// StartDeliverer runs DeliverDue every interval in the background until ctx is
+// cancelled.
+func StartDeliverer(ctx context.Context, service Service, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := service.DeliverDue(ctx); err != nil {
+					logrus.WithContext(ctx).Errorf("deliver webhooks error: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// DeliverDue sends a batch of due deliveries in parallel. Failures are retried
+// with exponential backoff until MaxAttempts, then marked dead.
+func (s *service) DeliverDue(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "webhook.DeliverDue")
+	defer span.End()
+
+	// lease ต้องนานกว่าเวลาส่งหนึ่งครั้ง ไม่งั้นอีก instance จะหยิบไปส่งซ้ำ
+	deliveries, err := s.repo.ClaimDue(ctx, time.Now(), 2*s.config.Timeout, s.config.BatchSize)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("claim webhook deliveries error: %v", err)
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			s.attempt(ctx, delivery)
+			if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+				logrus.WithContext(ctx).Errorf("update webhook delivery error: %v", err)
+			}
+		}(delivery)
+	}
+	wg.Wait()
+	return nil
+}
+
+func (s *service) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+
+	if delivery.Subscription == nil {
+		delivery.Status = models.WebhookDeliveryDead
+		delivery.LastError = "webhook subscription was deleted"
+		return
+	}
+
+	statusCode, err := s.send(ctx, delivery)
+	delivery.ResponseStatus = statusCode
+	if err == nil {
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	fields := logrus.Fields{
+		"delivery_id":     delivery.ID,
+		"subscription_id": delivery.SubscriptionID,
+		"event_type":      delivery.EventType,
+		"attempts":        delivery.Attempts,
+	}
+	if delivery.Attempts >= s.config.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryDead
+		logrus.WithContext(ctx).WithFields(fields).Warnf("webhook delivery dead: %v", err)
+		return
+	}
+	delivery.NextAttemptAt = now.Add(s.backoff(delivery.Attempts))
+	logrus.WithContext(ctx).WithFields(fields).Infof("webhook delivery failed, retrying at %s: %v", delivery.NextAttemptAt.Format(time.RFC3339), err)
+}
+
+func (s *service) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "food-delivery-webhooks/1")
+	request.Header.Set(HeaderEventID, delivery.EventID)
+	request.Header.Set(HeaderEventType, delivery.EventType)
+	request.Header.Set(HeaderSignature, Sign(delivery.Subscription.Secret.Plain(), time.Now(), delivery.Payload))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBody))
+		return response.StatusCode, fmt.Errorf("unexpected status %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	io.Copy(io.Discard, response.Body)
+	return response.StatusCode, nil
+}
+
+// backoff doubles the delay after every failed attempt, with up to 20% jitter
+// so deliveries that failed together do not retry together.
+func (s *service) backoff(attempts int) time.Duration {
+	delay := s.config.BaseDelay << (attempts - 1)
+	if delay > s.config.MaxDelay || delay <= 0 {
+		delay = s.config.MaxDelay
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
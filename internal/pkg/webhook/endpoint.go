@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/validation"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateSubscription Create webhook
+// @Summary Create a webhook subscription
+// @Description Subscribe a partner URL to events (order.created, order.status_changed, product.updated, promotion.redeemed). Deliveries are signed with HMAC-SHA256 in the X-Webhook-Signature header as "t=<unix time>,v1=<hex hmac of '<t>.<body>'>". The secret is only returned once. (admin)
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param request body CreateSubscriptionRequest true "Webhook subscription"
+// @Success 201 {object} CreateSubscriptionResponse
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /admin/webhooks [post]
+func CreateSubscription(c *fiber.Ctx, service Service) error {
+	request, err := validation.BindAndValidate[CreateSubscriptionRequest](c)
+	if err != nil {
+		return err
+	}
+
+	response, err := service.CreateSubscription(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// GetSubscriptions List webhooks
+// @Summary List webhook subscriptions
+// @Description List webhook subscriptions (admin)
+// @Tags admin
+// @Produce  json
+// @Success 200 {array} models.WebhookSubscription
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /admin/webhooks [get]
+func GetSubscriptions(c *fiber.Ctx, service Service) error {
+	subscriptions, err := service.GetSubscriptions(c.UserContext())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(subscriptions)
+}
+
+// DeleteSubscription Delete webhook
+// @Summary Delete a webhook subscription
+// @Description Stop sending events to the subscription. Its pending deliveries are marked dead instead of sent. (admin)
+// @Tags admin
+// @Produce  json
+// @Param id path int true "Webhook subscription ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /admin/webhooks/{id} [delete]
+func DeleteSubscription(c *fiber.Ctx, service Service) error {
+	subscriptionID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid webhook subscription ID")
+	}
+
+	if err := service.DeleteSubscription(c.UserContext(), &get.GetOne[uint]{ID: uint(subscriptionID)}); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Webhook subscription deleted",
+	})
+}
+
+// GetDeliveries List webhook deliveries
+// @Summary List webhook deliveries
+// @Description List deliveries, newest first, optionally by subscription and status (pending, succeeded, dead) (admin)
+// @Tags admin
+// @Produce  json
+// @Param subscription_id query int false "Webhook subscription ID"
+// @Param status query string false "Delivery status"
+// @Param limit query int false "Max results (default 50, max 100)"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /admin/webhooks/deliveries [get]
+func GetDeliveries(c *fiber.Ctx, service Service) error {
+	request, err := validation.BindAndValidate[DeliveriesRequest](c)
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := service.GetDeliveries(c.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(deliveries)
+}
+
+// Replay Replay webhook delivery
+// @Summary Replay a webhook delivery
+// @Description Send a delivered or dead delivery again. The event keeps its ID so partners can drop duplicates. (admin)
+// @Tags admin
+// @Produce  json
+// @Param id path int true "Webhook delivery ID"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 403 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Security ServiceKeyAuth
+// @Router /admin/webhooks/deliveries/{id}/replay [post]
+func Replay(c *fiber.Ctx, service Service) error {
+	deliveryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return apperror.Validation("INVALID_ID", "Invalid webhook delivery ID")
+	}
+
+	delivery, err := service.Replay(c.UserContext(), &get.GetOne[uint]{ID: uint(deliveryID)})
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(delivery)
+}
@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"context"
+	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	FindSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error)
+	FindSubscriptionByID(ctx context.Context, id uint, subscription *models.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id uint) error
+	CreateDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error
+	FindDeliveries(ctx context.Context, request *DeliveriesRequest) ([]*models.WebhookDelivery, error)
+	FindDeliveryByID(ctx context.Context, id uint, delivery *models.WebhookDelivery) error
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return database.Conn(ctx, r.db).Create(subscription).Error
+}
+
+func (r *repository) FindSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	var subscriptions []*models.WebhookSubscription
+	if err := database.Conn(ctx, r.db).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *repository) FindSubscriptionByID(ctx context.Context, id uint, subscription *models.WebhookSubscription) error {
+	return database.Conn(ctx, r.db).First(subscription, id).Error
+}
+
+func (r *repository) DeleteSubscription(ctx context.Context, id uint) error {
+	return database.Conn(ctx, r.db).Delete(&models.WebhookSubscription{}, id).Error
+}
+
+func (r *repository) CreateDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	return database.Conn(ctx, r.db).Create(deliveries).Error
+}
+
+func (r *repository) FindDeliveries(ctx context.Context, request *DeliveriesRequest) ([]*models.WebhookDelivery, error) {
+	query := database.Conn(ctx, r.db).Order("id DESC").Limit(request.Limit)
+	if request.SubscriptionID != 0 {
+		query = query.Where("subscription_id = ?", request.SubscriptionID)
+	}
+	if request.Status != "" {
+		query = query.Where("status = ?", request.Status)
+	}
+
+	var deliveries []*models.WebhookDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *repository) FindDeliveryByID(ctx context.Context, id uint, delivery *models.WebhookDelivery) error {
+	return database.Conn(ctx, r.db).Preload("Subscription").First(delivery, id).Error
+}
+
+// ClaimDue locks due deliveries and pushes their next attempt back by lease,
+// so other instances skip them while they are sent. If the process dies
+// mid-send they become due again once the lease runs out.
+func (r *repository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	err := database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+			Order("next_attempt_at").Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).
+			UpdateColumn("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+
+	subscriptionIDs := make([]uint, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		subscriptionIDs = append(subscriptionIDs, delivery.SubscriptionID)
+	}
+	var subscriptions []*models.WebhookSubscription
+	if err := database.Conn(ctx, r.db).Where("id IN ?", subscriptionIDs).Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*models.WebhookSubscription, len(subscriptions))
+	for _, subscription := range subscriptions {
+		byID[subscription.ID] = subscription
+	}
+	// subscription ที่ถูกลบไปแล้วจะได้ nil
+	for _, delivery := range deliveries {
+		delivery.Subscription = byID[delivery.SubscriptionID]
+	}
+	return deliveries, nil
+}
+
+func (r *repository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return database.Conn(ctx, r.db).Omit("Subscription").Save(delivery).Error
+}
@@ -0,0 +1,20 @@
+package webhook
+
+import "food-delivery-workshop/internal/models"
+
+type CreateSubscriptionRequest struct {
+	URL        string   `json:"url" validate:"required,http_url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,unique,dive,oneof=order.created order.status_changed product.updated promotion.redeemed"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=128"` // ว่างไว้ให้ระบบสุ่มให้
+}
+
+type CreateSubscriptionResponse struct {
+	Subscription *models.WebhookSubscription `json:"subscription"`
+	Secret       string                      `json:"secret"` // แสดงครั้งเดียวตอนสร้าง
+}
+
+type DeliveriesRequest struct {
+	SubscriptionID uint   `query:"subscription_id"`
+	Status         string `query:"status" validate:"omitempty,oneof=pending succeeded dead"`
+	Limit          int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
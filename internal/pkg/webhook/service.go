@@ -0,0 +1,205 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"food-delivery-workshop/internal/core/pii"
+	"food-delivery-workshop/internal/core/tracing"
+	"food-delivery-workshop/internal/get"
+	"food-delivery-workshop/internal/models"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Publisher queues an event for every subscription that wants it.
+type Publisher interface {
+	Publish(ctx context.Context, event *Envelope) error
+}
+
+type Service interface {
+	Publisher
+	CreateSubscription(ctx context.Context, request *CreateSubscriptionRequest) (*CreateSubscriptionResponse, error)
+	GetSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, request *get.GetOne[uint]) error
+	GetDeliveries(ctx context.Context, request *DeliveriesRequest) ([]*models.WebhookDelivery, error)
+	Replay(ctx context.Context, request *get.GetOne[uint]) (*models.WebhookDelivery, error)
+	DeliverDue(ctx context.Context) error
+}
+
+type Config struct {
+	MaxAttempts int           // ครบแล้วย้ายไป dead
+	BaseDelay   time.Duration // รอก่อนส่งซ้ำครั้งแรก แล้วเพิ่มเป็นเท่าตัว
+	MaxDelay    time.Duration
+	Timeout     time.Duration // ต่อการส่งหนึ่งครั้ง
+	BatchSize   int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts: 8,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    6 * time.Hour,
+		Timeout:     10 * time.Second,
+		BatchSize:   20,
+	}
+}
+
+const defaultDeliveriesLimit = 50
+
+type service struct {
+	repo   Repository
+	client *http.Client
+	config Config
+}
+
+func NewService(repo Repository, config Config) Service {
+	return &service{repo: repo, client: &http.Client{Timeout: config.Timeout}, config: config}
+}
+
+func (s *service) CreateSubscription(ctx context.Context, request *CreateSubscriptionRequest) (*CreateSubscriptionResponse, error) {
+	ctx, span := tracing.Start(ctx, "webhook.CreateSubscription")
+	defer span.End()
+
+	secret := request.Secret
+	if secret == "" {
+		generated, err := newSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	subscription := &models.WebhookSubscription{
+		URL:        request.URL,
+		Secret:     pii.String(secret),
+		EventTypes: request.EventTypes,
+	}
+	if err := s.repo.CreateSubscription(ctx, subscription); err != nil {
+		logrus.WithContext(ctx).Errorf("create webhook subscription error: %v", err)
+		return nil, err
+	}
+	return &CreateSubscriptionResponse{Subscription: subscription, Secret: secret}, nil
+}
+
+func (s *service) GetSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	ctx, span := tracing.Start(ctx, "webhook.GetSubscriptions")
+	defer span.End()
+
+	subscriptions, err := s.repo.FindSubscriptions(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find webhook subscriptions error: %v", err)
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (s *service) DeleteSubscription(ctx context.Context, request *get.GetOne[uint]) error {
+	ctx, span := tracing.Start(ctx, "webhook.DeleteSubscription")
+	defer span.End()
+
+	subscription := &models.WebhookSubscription{}
+	if err := s.repo.FindSubscriptionByID(ctx, request.GetID(), subscription); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("WEBHOOK_NOT_FOUND", "webhook subscription not found")
+		}
+		logrus.WithContext(ctx).Errorf("find webhook subscription error: %v", err)
+		return err
+	}
+	return s.repo.DeleteSubscription(ctx, subscription.ID)
+}
+
+func (s *service) GetDeliveries(ctx context.Context, request *DeliveriesRequest) ([]*models.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "webhook.GetDeliveries")
+	defer span.End()
+
+	if request.Limit == 0 {
+		request.Limit = defaultDeliveriesLimit
+	}
+	deliveries, err := s.repo.FindDeliveries(ctx, request)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("find webhook deliveries error: %v", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Replay queues the delivery's event again as a new delivery with the same
+// event ID, keeping the original as history.
+func (s *service) Replay(ctx context.Context, request *get.GetOne[uint]) (*models.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "webhook.Replay")
+	defer span.End()
+
+	original := &models.WebhookDelivery{}
+	if err := s.repo.FindDeliveryByID(ctx, request.GetID(), original); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("DELIVERY_NOT_FOUND", "webhook delivery not found")
+		}
+		logrus.WithContext(ctx).Errorf("find webhook delivery error: %v", err)
+		return nil, err
+	}
+	if original.Subscription == nil {
+		return nil, apperror.Conflict("WEBHOOK_DELETED", "the webhook subscription of this delivery was deleted")
+	}
+	if original.Status == models.WebhookDeliveryPending {
+		return nil, apperror.Conflict("DELIVERY_PENDING", "the delivery is still being retried")
+	}
+
+	replay := &models.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now(),
+		ReplayOfID:     &original.ID,
+	}
+	if err := s.repo.CreateDeliveries(ctx, []*models.WebhookDelivery{replay}); err != nil {
+		logrus.WithContext(ctx).Errorf("create webhook delivery error: %v", err)
+		return nil, err
+	}
+	return replay, nil
+}
+
+// Publish stores one pending delivery per matching subscription; sending
+// happens in the background (see StartDeliverer). Publishing the same event ID
+// again sends a duplicate that partners can drop by X-Webhook-Id.
+func (s *service) Publish(ctx context.Context, event *Envelope) error {
+	ctx, span := tracing.Start(ctx, "webhook.Publish")
+	defer span.End()
+
+	subscriptions, err := s.repo.FindSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []*models.WebhookDelivery
+	for _, subscription := range subscriptions {
+		if !slices.Contains(subscription.EventTypes, event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, &models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return s.repo.CreateDeliveries(ctx, deliveries)
+}
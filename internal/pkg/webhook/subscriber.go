@@ -0,0 +1,34 @@
+package webhook
+
+import (
+	"context"
+	"food-delivery-workshop/internal/core/events"
+)
+
+// Subscribe publishes webhooks for domain events as the outbox relays them,
+// so a webhook is only sent for changes that were committed. The webhook ID
+// comes from the outbox message, which keeps it the same when the relay
+// retries a message.
+func Subscribe(bus *events.Bus, publisher Publisher) {
+	events.On(bus, func(ctx context.Context, message events.Message, event events.OrderPlaced) error {
+		return publish(ctx, publisher, message, EventOrderCreated, event.Order)
+	})
+	events.On(bus, func(ctx context.Context, message events.Message, event events.OrderStatusChanged) error {
+		return publish(ctx, publisher, message, EventOrderStatusChanged, event)
+	})
+	events.On(bus, func(ctx context.Context, message events.Message, event events.ProductUpdated) error {
+		return publish(ctx, publisher, message, EventProductUpdated, event.Product)
+	})
+	events.On(bus, func(ctx context.Context, message events.Message, event events.PromotionRedeemed) error {
+		return publish(ctx, publisher, message, EventPromotionRedeemed, event)
+	})
+}
+
+func publish(ctx context.Context, publisher Publisher, message events.Message, eventType string, data interface{}) error {
+	return publisher.Publish(ctx, &Envelope{
+		ID:        "evt_" + message.ID,
+		Type:      eventType,
+		CreatedAt: message.OccurredAt,
+		Data:      data,
+	})
+}
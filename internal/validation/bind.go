@@ -0,0 +1,25 @@
+package validation
+
+import (
+	"food-delivery-workshop/internal/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BindAndValidate parses the request into a new T and validates it. GET
+// requests are read from the query string, everything else from the body.
+func BindAndValidate[T any](c *fiber.Ctx) (*T, error) {
+	request := new(T)
+	if c.Method() == fiber.MethodGet {
+		if err := c.QueryParser(request); err != nil {
+			return nil, apperror.Validation("INVALID_QUERY", "Invalid query parameters")
+		}
+	} else if err := c.BodyParser(request); err != nil {
+		return nil, apperror.Validation("INVALID_BODY", "Invalid request body")
+	}
+
+	if err := Struct(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
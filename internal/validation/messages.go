@@ -0,0 +1,75 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+)
+
+type translation struct {
+	en string
+	th string
+}
+
+// messages holds the English and Thai text for each rule. {field} and
+// {param} are replaced with the field name and the rule parameter.
+var messages = map[string]translation{
+	"required":      {"{field} is required", "กรุณาระบุ {field}"},
+	"required_with": {"{field} is required when {param} is set", "กรุณาระบุ {field} เมื่อระบุ {param}"},
+	"email":         {"{field} must be a valid email address", "{field} ต้องเป็นอีเมลที่ถูกต้อง"},
+	"http_url":      {"{field} must be a valid http or https URL", "{field} ต้องเป็น URL แบบ http หรือ https ที่ถูกต้อง"},
+	"oneof":         {"{field} must be one of: {param}", "{field} ต้องเป็นค่าใดค่าหนึ่งต่อไปนี้: {param}"},
+	"latitude":      {"{field} must be a valid latitude", "{field} ต้องเป็นละติจูดที่ถูกต้อง"},
+	"longitude":     {"{field} must be a valid longitude", "{field} ต้องเป็นลองจิจูดที่ถูกต้อง"},
+	"datetime":      {"{field} must match the format {param}", "{field} ต้องอยู่ในรูปแบบ {param}"},
+	"thai_phone":    {"{field} must be a 10 digit mobile number starting with 06, 08 or 09", "{field} ต้องเป็นเบอร์มือถือ 10 หลักที่ขึ้นต้นด้วย 06, 08 หรือ 09"},
+	"thai_id":       {"{field} must be a valid 13 digit Thai national ID", "{field} ต้องเป็นเลขบัตรประชาชน 13 หลักที่ถูกต้อง"},
+	"money":         {"{field} must be a positive amount with at most 2 decimal places", "{field} ต้องเป็นจำนวนเงินที่มากกว่า 0 และมีทศนิยมไม่เกิน 2 ตำแหน่ง"},
+	"password":      {"{field} must contain both upper and lower case english letters", "{field} ต้องมีตัวอักษรภาษาอังกฤษทั้งตัวพิมพ์ใหญ่และตัวพิมพ์เล็ก"},
+	"api_scope":     {"{field} is not a known API key scope", "{field} ไม่ใช่ scope ของ API key ที่รู้จัก"},
+	"unique":        {"{field} must not contain duplicates", "{field} ต้องไม่มีค่าซ้ำกัน"},
+}
+
+// sizeMessages word min, max and len by the kind of field they apply to.
+var sizeMessages = map[string]map[reflect.Kind]translation{
+	"min": {
+		reflect.String: {"{field} must be at least {param} characters", "{field} ต้องมีอย่างน้อย {param} ตัวอักษร"},
+		reflect.Slice:  {"{field} must contain at least {param} items", "{field} ต้องมีอย่างน้อย {param} รายการ"},
+		reflect.Int:    {"{field} must be {param} or greater", "{field} ต้องมีค่าตั้งแต่ {param} ขึ้นไป"},
+	},
+	"max": {
+		reflect.String: {"{field} must be at most {param} characters", "{field} ต้องมีไม่เกิน {param} ตัวอักษร"},
+		reflect.Slice:  {"{field} must contain at most {param} items", "{field} ต้องมีไม่เกิน {param} รายการ"},
+		reflect.Int:    {"{field} must be {param} or less", "{field} ต้องมีค่าไม่เกิน {param}"},
+	},
+	"len": {
+		reflect.String: {"{field} must be exactly {param} characters", "{field} ต้องมี {param} ตัวอักษร"},
+		reflect.Slice:  {"{field} must contain exactly {param} items", "{field} ต้องมี {param} รายการ"},
+		reflect.Int:    {"{field} must be {param}", "{field} ต้องมีค่าเท่ากับ {param}"},
+	},
+}
+
+var fallback = translation{"{field} is invalid", "{field} ไม่ถูกต้อง"}
+
+func message(tag string, kind reflect.Kind, field, param string) (string, string) {
+	t, ok := messages[tag]
+	if sizes, sized := sizeMessages[tag]; sized {
+		t, ok = sizes[sizeKind(kind)]
+	}
+	if !ok {
+		t = fallback
+	}
+
+	replacer := strings.NewReplacer("{field}", field, "{param}", param)
+	return replacer.Replace(t.en), replacer.Replace(t.th)
+}
+
+// sizeKind groups kinds that share the same min/max wording.
+func sizeKind(kind reflect.Kind) reflect.Kind {
+	switch kind {
+	case reflect.String:
+		return reflect.String
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return reflect.Slice
+	}
+	return reflect.Int
+}
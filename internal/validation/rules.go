@@ -0,0 +1,88 @@
+package validation
+
+import (
+	"food-delivery-workshop/internal/auth"
+	"math"
+	"reflect"
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var rules = map[string]validator.Func{
+	"thai_phone": isThaiPhone,
+	"thai_id":    isThaiNationalID,
+	"money":      isMoney,
+	"password":   isStrongPassword,
+	"api_scope":  isAPIScope,
+}
+
+var thaiPhoneRegex = regexp.MustCompile(`^0[689]\d{8}$`)
+
+// isThaiPhone accepts 10 digit mobile numbers starting with 06, 08 or 09.
+func isThaiPhone(fl validator.FieldLevel) bool {
+	return thaiPhoneRegex.MatchString(fl.Field().String())
+}
+
+func isThaiNationalID(fl validator.FieldLevel) bool {
+	return ValidThaiNationalID(fl.Field().String())
+}
+
+// ValidThaiNationalID checks the 13 digit format and the mod 11 check digit.
+func ValidThaiNationalID(id string) bool {
+	if len(id) != 13 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 13; i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return false
+		}
+		if i < 12 {
+			sum += int(id[i]-'0') * (13 - i)
+		}
+	}
+	return (11-sum%11)%10 == int(id[12]-'0')
+}
+
+// isMoney accepts positive amounts with at most two decimal places (satang).
+func isMoney(fl validator.FieldLevel) bool {
+	var amount float64
+	switch fl.Field().Kind() {
+	case reflect.Float32, reflect.Float64:
+		amount = fl.Field().Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		amount = float64(fl.Field().Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		amount = float64(fl.Field().Uint())
+	default:
+		return false
+	}
+	if amount <= 0 {
+		return false
+	}
+	cents := amount * 100
+	return math.Abs(cents-math.Round(cents)) < 1e-6
+}
+
+// isStrongPassword requires both upper and lower case english letters.
+func isStrongPassword(fl validator.FieldLevel) bool {
+	var hasUpper, hasLower bool
+	for _, char := range fl.Field().String() {
+		if char >= 'a' && char <= 'z' {
+			hasLower = true
+		} else if char >= 'A' && char <= 'Z' {
+			hasUpper = true
+		}
+	}
+	return hasUpper && hasLower
+}
+
+func isAPIScope(fl validator.FieldLevel) bool {
+	for _, scope := range auth.Scopes {
+		if fl.Field().String() == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package validation
+
+import (
+	"errors"
+	"food-delivery-workshop/internal/apperror"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate is shared by every request; validator caches struct metadata so
+// one instance is much cheaper than calling validator.New() per request.
+var validate = newValidator()
+
+// FieldError describes one invalid field, with the message in English and Thai.
+type FieldError struct {
+	Field     string `json:"field"`
+	Rule      string `json:"rule"`
+	Param     string `json:"param,omitempty"`
+	Message   string `json:"message"`
+	MessageTH string `json:"message_th"`
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(fieldName)
+	for tag, rule := range rules {
+		if err := v.RegisterValidation(tag, rule); err != nil {
+			panic(err)
+		}
+	}
+	return v
+}
+
+// fieldName reports fields by the name the client sent: the json tag, or the
+// query tag for query string requests.
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "query"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Struct validates request and returns a VALIDATION_FAILED error listing every invalid field.
+func Struct(request interface{}) error {
+	err := validate.Struct(request)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return apperror.Internal(err)
+	}
+
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, newFieldError(fe))
+	}
+	return apperror.Validation("VALIDATION_FAILED", "request validation failed").WithDetails(map[string]interface{}{
+		"fields": fields,
+	})
+}
+
+func newFieldError(fe validator.FieldError) FieldError {
+	field := fieldPath(fe)
+	en, th := message(fe.Tag(), fe.Kind(), field, fe.Param())
+	return FieldError{
+		Field:     field,
+		Rule:      fe.Tag(),
+		Param:     fe.Param(),
+		Message:   en,
+		MessageTH: th,
+	}
+}
+
+// fieldPath turns "CreateRequest.Request.cart_items[0].quantity" into
+// "cart_items[0].quantity", dropping the request type and embedded structs.
+func fieldPath(fe validator.FieldError) string {
+	parts := strings.Split(fe.Namespace(), ".")[1:]
+	path := make([]string, 0, len(parts))
+	for i, part := range parts {
+		// embedded structs have no json tag, so they keep their Go type name
+		if i < len(parts)-1 && part[0] >= 'A' && part[0] <= 'Z' {
+			continue
+		}
+		path = append(path, part)
+	}
+	return strings.Join(path, ".")
+}
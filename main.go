@@ -1,39 +1,250 @@
 package main
 
 import (
+	"context"
 	"food-delivery-workshop/internal/core/database"
+	"food-delivery-workshop/internal/core/events"
+	"food-delivery-workshop/internal/core/idempotency"
+	"food-delivery-workshop/internal/core/lockout"
+	"food-delivery-workshop/internal/core/logging"
+	"food-delivery-workshop/internal/core/mailer"
+	"food-delivery-workshop/internal/core/metrics"
+	"food-delivery-workshop/internal/core/oidc"
+	"food-delivery-workshop/internal/core/pii"
+	"food-delivery-workshop/internal/core/ratelimit"
+	"food-delivery-workshop/internal/core/pubsub"
+	"food-delivery-workshop/internal/core/sms"
+	"food-delivery-workshop/internal/core/tracing"
+	"food-delivery-workshop/internal/grpcapi"
+	"food-delivery-workshop/internal/pkg/apikey"
 	cart "food-delivery-workshop/internal/pkg/cart"
+	"food-delivery-workshop/internal/pkg/eta"
+	"food-delivery-workshop/internal/pkg/health"
+	"food-delivery-workshop/internal/pkg/order"
 	"food-delivery-workshop/internal/pkg/product"
 	"food-delivery-workshop/internal/pkg/promotion"
+	"food-delivery-workshop/internal/pkg/restaurant"
+	"food-delivery-workshop/internal/pkg/rider"
+	"food-delivery-workshop/internal/pkg/sso"
 	"food-delivery-workshop/internal/pkg/user"
+	"food-delivery-workshop/internal/pkg/webhook"
 	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	routes "food-delivery-workshop/internal/middleware"
 	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
 )
 
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
 
+// @securityDefinitions.apikey ServiceKeyAuth
+// @in header
+// @name X-API-Key
+
 func main() {
-	database.ConnectDB()
+	logConfig, err := logging.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("configure logging: %v", err)
+	}
+	logging.Setup(logConfig)
+
+	keyring, err := pii.LoadKeyring()
+	if err != nil {
+		log.Fatalf("load pii keys: %v", err)
+	}
+	pii.SetKeyring(keyring)
+
+	mail, err := mailer.FromEnv()
+	if err != nil {
+		log.Fatalf("configure mailer: %v", err)
+	}
+	smsSender, err := sms.FromEnv()
+	if err != nil {
+		log.Fatalf("configure sms: %v", err)
+	}
+
+	oidcConfigs, err := oidc.ConfigsFromEnv()
+	if err != nil {
+		log.Fatalf("configure oidc: %v", err)
+	}
+	// OIDC_DEV_IDP_ADDR เปิด identity provider จำลองไว้ทดสอบ social login บนเครื่อง
+	if addr := os.Getenv("OIDC_DEV_IDP_ADDR"); addr != "" {
+		redirectURL := os.Getenv("OIDC_DEV_REDIRECT_URL")
+		if redirectURL == "" {
+			redirectURL = "http://localhost:3000/auth/dev/callback"
+		}
+		config, err := oidc.ListenStub(addr, redirectURL)
+		if err != nil {
+			log.Fatalf("start stub identity provider: %v", err)
+		}
+		oidcConfigs = append(oidcConfigs, config)
+	}
+	var oidcProviders []*oidc.Provider
+	for _, config := range oidcConfigs {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(config))
+	}
+
+	ctx := context.Background()
+	// OTEL_TRACES_EXPORTER=otlp ส่ง trace ไป collector, stdout สำหรับดูบนเครื่อง
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		log.Fatalf("configure tracing: %v", err)
+	}
+
+	database.ConnectDB(logging.NewGormLogger(logConfig.SlowQueryThreshold))
+	if err := metrics.RegisterDB(database.DB); err != nil {
+		log.Fatalf("register database metrics: %v", err)
+	}
+	if err := tracing.RegisterDB(database.DB); err != nil {
+		log.Fatalf("register database tracing: %v", err)
+	}
+
+	// domain event ถูกบันทึกลง outbox พร้อมข้อมูล แล้ว relay ส่งให้ subscriber บน bus
+	bus := events.NewBus()
+	for _, name := range []string{events.NameProductPriceChanged, events.NameProductUpdated, events.NameCartUpdated,
+		events.NamePromotionApplied, events.NamePromotionRedeemed, events.NameOrderPlaced, events.NameOrderStatusChanged} {
+		bus.Subscribe(name, func(ctx context.Context, message events.Message) error {
+			logrus.Debugf("event %s %s: %s", message.Name, message.ID, message.Payload)
+			return nil
+		})
+	}
+	outbox := events.NewOutbox(database.DB, bus, events.DefaultConfig())
 
 	userRepository := user.NewRepository(database.DB)
-	userService := user.NewService(userRepository)
+	attemptStore := lockout.NewMemoryStore()
+	userService := user.NewService(userRepository, mail, smsSender,
+		lockout.NewLimiter(attemptStore, "account:", lockout.DefaultAccountPolicy),
+		lockout.NewLimiter(attemptStore, "ip:", lockout.DefaultIPPolicy))
+	webhookRepository := webhook.NewRepository(database.DB)
+	webhookService := webhook.NewService(webhookRepository, webhook.DefaultConfig())
+	webhook.Subscribe(bus, webhookService)
 	productRepository := product.NewRepository(database.DB)
-	productService := product.NewService(productRepository)
+	productService := product.NewService(productRepository, outbox)
 	promotionRepository := promotion.NewRepository(database.DB)
 	promotionService := promotion.NewService(promotionRepository)
+	restaurantRepository := restaurant.NewRepository(database.DB)
+	restaurantService := restaurant.NewService(restaurantRepository)
+	etaRepository := eta.NewRepository(database.DB)
+	etaService := eta.NewService(etaRepository, eta.DefaultConfig())
 	cartRepository := cart.NewRepository(database.DB)
-	cartService := cart.NewService(cartRepository,promotionRepository, productRepository)
+	cartService := cart.NewService(cartRepository,promotionRepository, productRepository, restaurantService, etaService, outbox)
+	hub := pubsub.NewHub(16)
+	orderRepository := order.NewRepository(database.DB)
+	orderService := order.NewService(orderRepository, cartService, cartRepository, restaurantService, etaService, hub, outbox)
+	riderRepository := rider.NewRepository(database.DB)
+	riderService := rider.NewService(riderRepository, orderRepository, orderService, hub, 30*time.Second)
+	ssoRepository := sso.NewRepository(database.DB)
+	ssoService := sso.NewService(ssoRepository, userRepository, oidcProviders, oidc.NewMemoryStateStore())
+	apiKeyRepository := apikey.NewRepository(database.DB)
+	apiKeyService := apikey.NewService(apiKeyRepository)
+	healthService := health.NewService(database.DB)
+
+	// ADMIN_API_KEY ใช้สร้าง admin key แรก หลังจากนั้นสร้าง key อื่นผ่าน /admin/api-keys
+	if key := os.Getenv("ADMIN_API_KEY"); key != "" {
+		if err := apiKeyService.Bootstrap(ctx, key); err != nil {
+			log.Fatalf("bootstrap admin api key: %v", err)
+		}
+	}
+
+	go func() {
+		rotated, err := userService.RotatePII(ctx)
+		if err != nil {
+			logrus.Errorf("rotate pii error: %v", err)
+			return
+		}
+		if rotated > 0 {
+			logrus.Infof("re-encrypted personal data of %d users", rotated)
+		}
 
-	app := fiber.New()
+		rotated, err = orderService.RotatePII(ctx)
+		if err != nil {
+			logrus.Errorf("rotate order pii error: %v", err)
+			return
+		}
+		if rotated > 0 {
+			logrus.Infof("re-encrypted delivery addresses of %d orders", rotated)
+		}
+	}()
+	rider.StartDispatcher(ctx, riderService, 5*time.Second)
+	order.StartScheduler(ctx, orderService, time.Minute)
+	webhook.StartDeliverer(ctx, webhookService, 5*time.Second)
+	outbox.Start(ctx, 5*time.Second)
 
-	routes.SetupRoutes(app, userService, productService, cartService, promotionService)
+	app := fiber.New(fiber.Config{
+		ErrorHandler: routes.ErrorHandler,
+	})
+	app.Use(routes.RequestID())
+	app.Use(routes.Tracing())
+	app.Use(routes.AccessLog())
+	app.Use(routes.Metrics())
+	app.Use(routes.RequestTimeout(30 * time.Second))
 
+	// IDEMPOTENCY_TTL คือเวลาที่เก็บ response ไว้ตอบ request ที่ส่งซ้ำด้วย Idempotency-Key เดิม
+	idempotencyTTL := 24 * time.Hour
+	if value := os.Getenv("IDEMPOTENCY_TTL"); value != "" {
+		idempotencyTTL, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("parse IDEMPOTENCY_TTL: %v", err)
+		}
+	}
+	idempotent := routes.Idempotency(idempotency.NewMemoryStore(), idempotencyTTL)
+
+	// ปรับ policy ได้ด้วย RATE_LIMIT_AUTH, RATE_LIMIT_PROMOTION เช่น "10/1m"
+	rateLimitPolicies, err := ratelimit.PoliciesFromEnv()
+	if err != nil {
+		log.Fatalf("configure rate limits: %v", err)
+	}
+	rateLimiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), rateLimitPolicies)
+
+	routes.SetupRoutes(app, userService, productService, cartService, promotionService, restaurantService, orderService, riderService, ssoService, apiKeyService, webhookService, idempotent, rateLimiter, healthService)
+
+	// gRPC ใช้ service ชุดเดียวกับ REST, GRPC_REFLECTION=true สำหรับดีบักบนเครื่องด้วย grpcurl
+	grpcAddr := os.Getenv("GRPC_ADDR")
+	if grpcAddr == "" {
+		grpcAddr = ":9090"
+	}
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatalf("listen grpc: %v", err)
+	}
+	grpcServer := grpcapi.NewServer(grpcapi.Services{
+		Product:     productService,
+		Promotion:   promotionService,
+		Cart:        cartService,
+		User:        userService,
+		APIKey:      apiKeyService,
+		RateLimiter: rateLimiter,
+	}, os.Getenv("GRPC_REFLECTION") == "true")
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logrus.Errorf("grpc server error: %v", err)
+		}
+	}()
+
+	// ปิด server เมื่อได้ SIGTERM เพื่อให้ส่ง span ที่ค้างอยู่ออกไปก่อนจบ process
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		grpcServer.GracefulStop()
+		if err := app.Shutdown(); err != nil {
+			logrus.Errorf("shutdown http server error: %v", err)
+		}
+	}()
 
 	if err := app.Listen(":3000"); err != nil {
 		log.Fatal(err)
 	}
 
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logrus.Errorf("flush traces error: %v", err)
+	}
 }